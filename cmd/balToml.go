@@ -0,0 +1,158 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// BallerinaToml is the manifest of a ballerina package
+type BallerinaToml struct {
+	Package      BalPackage                      `toml:"package"`
+	BuildOptions BalBuildOptions                 `toml:"build-options"`
+	Dependencies []BalDependency                 `toml:"dependency"`
+	Platforms    map[string]BalPlatformLibraries `toml:"platform"`
+}
+
+type BalPackage struct {
+	Org          string   `toml:"org"`
+	Name         string   `toml:"name"`
+	Version      string   `toml:"version"`
+	Distribution string   `toml:"distribution"`
+	Authors      []string `toml:"authors"`
+	Keywords     []string `toml:"keywords"`
+	License      []string `toml:"license"`
+	Repository   string   `toml:"repository"`
+	Export       []string `toml:"export"`
+}
+
+type BalBuildOptions struct {
+	ObservabilityIncluded bool   `toml:"observabilityIncluded"`
+	Offline               bool   `toml:"offline"`
+	SkipTests             bool   `toml:"skipTests"`
+	TestReport            bool   `toml:"testReport"`
+	CodeCoverage          bool   `toml:"codeCoverage"`
+	Sticky                bool   `toml:"sticky"`
+	Graalvm               bool   `toml:"graalvm"`
+	Cloud                 string `toml:"cloud"`
+	TargetDir             string `toml:"targetDir"`
+}
+
+// BalDependency is a [[dependency]] entry locking a package to a version or a repository
+type BalDependency struct {
+	Org        string `toml:"org"`
+	Name       string `toml:"name"`
+	Version    string `toml:"version"`
+	Repository string `toml:"repository"`
+}
+
+func (d BalDependency) String() string {
+	dependency := d.Org + "/" + d.Name
+	if d.Version != "" {
+		dependency += " " + d.Version
+	}
+	if d.Repository != "" {
+		dependency += " (" + d.Repository + ")"
+	}
+	return dependency
+}
+
+// BalPlatformLibraries are the [[platform.<platform>.dependency]] entries of a platform such as java17
+type BalPlatformLibraries struct {
+	Dependencies []BalPlatformDependency `toml:"dependency"`
+}
+
+type BalPlatformDependency struct {
+	GroupId    string `toml:"groupId"`
+	ArtifactId string `toml:"artifactId"`
+	Version    string `toml:"version"`
+	Path       string `toml:"path"`
+	Scope      string `toml:"scope"`
+}
+
+func (d BalPlatformDependency) String() string {
+	var library string
+	if d.ArtifactId != "" {
+		library = d.GroupId + ":" + d.ArtifactId + ":" + d.Version
+	}
+	if d.Path != "" {
+		library = strings.TrimSpace(library + " " + d.Path)
+	}
+	if d.Scope != "" {
+		library += " [" + d.Scope + "]"
+	}
+	return library
+}
+
+// Settings lists the build options that are set, as key = value
+func (o BalBuildOptions) Settings() []string {
+	var settings []string
+	for _, option := range []struct {
+		key string
+		set bool
+	}{
+		{"observabilityIncluded", o.ObservabilityIncluded},
+		{"offline", o.Offline},
+		{"skipTests", o.SkipTests},
+		{"testReport", o.TestReport},
+		{"codeCoverage", o.CodeCoverage},
+		{"sticky", o.Sticky},
+		{"graalvm", o.Graalvm},
+	} {
+		if option.set {
+			settings = append(settings, option.key+" = true")
+		}
+	}
+	if o.Cloud != "" {
+		settings = append(settings, fmt.Sprintf("cloud = %q", o.Cloud))
+	}
+	if o.TargetDir != "" {
+		settings = append(settings, fmt.Sprintf("targetDir = %q", o.TargetDir))
+	}
+	return settings
+}
+
+// ReadBallerinaToml reads the Ballerina.toml of the project
+func ReadBallerinaToml(projectPath string) (BallerinaToml, error) {
+	var manifest BallerinaToml
+	content, err := os.ReadFile(filepath.Join(projectPath, "Ballerina.toml"))
+	if err != nil {
+		return manifest, err
+	}
+	if err := toml.Unmarshal(content, &manifest); err != nil {
+		return manifest, fmt.Errorf("invalid Ballerina.toml in %s: %v", projectPath, err)
+	}
+	return manifest, nil
+}
+
+// distributionMismatch reports whether the distribution the package was written for differs from the toolchain
+// version. Snapshot builds of a version match the version.
+func distributionMismatch(distribution, toolchainVersion string) bool {
+	if distribution == "" || toolchainVersion == "" {
+		return false
+	}
+	return strings.TrimSuffix(distribution, "-SNAPSHOT") != strings.TrimSuffix(toolchainVersion, "-SNAPSHOT")
+}
+
+// warnOnDistributionMismatch warns when the target is a project written for a different distribution
+func warnOnDistributionMismatch(toolchain Toolchain, targetPath string) {
+	if !isBallerinaProject(targetPath) {
+		return
+	}
+	manifest, err := ReadBallerinaToml(targetPath)
+	if err != nil {
+		return
+	}
+	if distributionMismatch(manifest.Package.Distribution, toolchain.Version) {
+		fmt.Fprintf(os.Stderr, "Warning: %s is written for distribution %s but the toolchain is %s\n",
+			manifest.Package.Name, manifest.Package.Distribution, toolchain.Version)
+	}
+}
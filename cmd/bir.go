@@ -23,25 +23,30 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 var birCmd = &cobra.Command{
-	Use:   "bir [path]",
+	Use:   "bir [path] [-- bal-flags...]",
 	Short: "Generate BIR for a given source file",
 	Run: func(cmd *cobra.Command, args []string) {
-		var targetPath string
-		if (len(args) == 1) {
-			targetPath = args[0]
-		} else {
-			targetPath = CurrentWorkingDir()
-		}
-		command, err := CreateCommand(viper.GetString("sourcePath"), viper.GetString("version"), targetPath, Build, false, "--dump-bir")
+		args, passThrough := SplitPassThroughArgs(cmd, args)
+		command, err := CreateCommand(CurrentToolchain(), TargetFromArgs(args), Build, DebugOptions{},
+			append([]string{"--dump-bir"}, passThrough...)...)
 		ConsumeError(err)
 		ConsumeError(ExecuteCommand(&command))
 	},
 }
 
+// DumpBir compiles the target with the given toolchain and returns the emitted BIR
+func DumpBir(toolchain Toolchain, targetPath string) (string, error) {
+	command, err := CreateCommand(toolchain, targetPath, Build, DebugOptions{}, "--dump-bir")
+	if err != nil {
+		return "", err
+	}
+	return CaptureCommandOutput(&command)
+}
+
 func init() {
 	rootCmd.AddCommand(birCmd)
+	addBuildFlagsFlag(birCmd.PersistentFlags())
 }
@@ -0,0 +1,199 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type CfgEdgeKind string
+
+const (
+	GotoEdge   CfgEdgeKind = "goto"
+	TrueEdge   CfgEdgeKind = "true"
+	FalseEdge  CfgEdgeKind = "false"
+	ReturnEdge CfgEdgeKind = "return"
+	ErrorEdge  CfgEdgeKind = "error"
+	PanicEdge  CfgEdgeKind = "panic"
+)
+
+// panicNode is the synthetic node used as the target of panics that are not covered by the error table
+const panicNode = "panic"
+
+type CfgEdge struct {
+	From string
+	To   string
+	Kind CfgEdgeKind
+}
+
+var (
+	birBranchPattern = regexp.MustCompile(`\?\s*(bb\d+)\s*:\s*(bb\d+)\s*;?$`)
+	birReturnPattern = regexp.MustCompile(`->\s*(bb\d+)\s*;?$`)
+)
+
+var birCfgCmd = &cobra.Command{
+	Use:   "cfg <path>",
+	Short: "Export the BIR control-flow graph of a function as a Graphviz DOT file",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("Please provide the path to ballerina source/project")
+			os.Exit(1)
+		}
+		functionName := viper.GetString("cfg_function")
+		if functionName == "" {
+			fmt.Println("Please provide the function to export using --function")
+			os.Exit(1)
+		}
+		dump, err := DumpBir(CurrentToolchain(), ResolveTarget(args[0]))
+		ConsumeError(err)
+		functions := ParseBir(dump)
+		function, ok := FindBirFunction(functions, functionName)
+		if !ok {
+			ConsumeError(fmt.Errorf("function %s not found in BIR, available functions: %s", functionName,
+				strings.Join(BirFunctionNames(functions), ", ")))
+		}
+		outputPath := viper.GetString("cfg_output")
+		if outputPath == "" {
+			outputPath = functionName + ".dot"
+		}
+		ConsumeError(os.WriteFile(outputPath, []byte(CfgToDot(function)), 0644))
+		fmt.Println("Control-flow graph written to", outputPath)
+		renderSvgIfPossible(outputPath)
+	},
+}
+
+// CfgEdges computes the control-flow edges of a function from the terminator of each basic block and the error table
+func CfgEdges(function BirFunction) []CfgEdge {
+	var edges []CfgEdge
+	protected := protectedBlocks(function)
+	for _, block := range function.Blocks {
+		if len(block.Instructions) == 0 {
+			continue
+		}
+		terminator := block.Instructions[len(block.Instructions)-1]
+		switch {
+		case strings.HasPrefix(terminator, "GOTO"):
+			if target := birBlockRefPattern.FindString(terminator); target != "" {
+				edges = append(edges, CfgEdge{From: block.Id, To: target, Kind: GotoEdge})
+			}
+		case birBranchPattern.MatchString(terminator):
+			match := birBranchPattern.FindStringSubmatch(terminator)
+			edges = append(edges, CfgEdge{From: block.Id, To: match[1], Kind: TrueEdge},
+				CfgEdge{From: block.Id, To: match[2], Kind: FalseEdge})
+		case birReturnPattern.MatchString(terminator):
+			match := birReturnPattern.FindStringSubmatch(terminator)
+			edges = append(edges, CfgEdge{From: block.Id, To: match[1], Kind: ReturnEdge})
+		case strings.HasPrefix(terminator, "panic"):
+			if _, ok := protected[block.Id]; !ok {
+				edges = append(edges, CfgEdge{From: block.Id, To: panicNode, Kind: PanicEdge})
+			}
+		}
+		for _, target := range protected[block.Id] {
+			edges = append(edges, CfgEdge{From: block.Id, To: target, Kind: ErrorEdge})
+		}
+	}
+	return edges
+}
+
+// protectedBlocks maps each basic block covered by an error table entry to the blocks handling its errors
+func protectedBlocks(function BirFunction) map[string][]string {
+	index := make(map[string]int, len(function.Blocks))
+	for i, block := range function.Blocks {
+		index[block.Id] = i
+	}
+	protected := make(map[string][]string)
+	for _, entry := range function.ErrorTable {
+		start, okStart := index[entry.TrapBB]
+		end, okEnd := index[entry.EndBB]
+		if !okStart || !okEnd {
+			continue
+		}
+		for i := start; i <= end; i++ {
+			id := function.Blocks[i].Id
+			protected[id] = append(protected[id], entry.TargetBB)
+		}
+	}
+	return protected
+}
+
+func CfgToDot(function BirFunction) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "digraph %s {\n", dotQuote(function.Name))
+	sb.WriteString("    node [shape=box, fontname=\"monospace\"];\n")
+	for _, block := range function.Blocks {
+		label := block.Id + "\\l"
+		for _, instruction := range block.Instructions {
+			label += dotEscape(instruction) + "\\l"
+		}
+		fmt.Fprintf(&sb, "    %s [label=\"%s\"];\n", block.Id, label)
+	}
+	edges := CfgEdges(function)
+	for _, edge := range edges {
+		if edge.To == panicNode {
+			fmt.Fprintf(&sb, "    %s [shape=octagon, color=red];\n", panicNode)
+			break
+		}
+	}
+	for _, edge := range edges {
+		fmt.Fprintf(&sb, "    %s -> %s [%s];\n", edge.From, edge.To, dotEdgeAttributes(edge.Kind))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func dotEdgeAttributes(kind CfgEdgeKind) string {
+	switch kind {
+	case TrueEdge:
+		return "label=\"true\", color=darkgreen"
+	case FalseEdge:
+		return "label=\"false\", color=orange"
+	case ReturnEdge:
+		return "label=\"return\", style=bold"
+	case ErrorEdge:
+		return "label=\"error\", style=dashed, color=red"
+	case PanicEdge:
+		return "label=\"panic\", style=dotted, color=red"
+	default:
+		return fmt.Sprintf("label=\"%s\"", kind)
+	}
+}
+
+func dotQuote(s string) string {
+	return "\"" + dotEscape(s) + "\""
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(s)
+}
+
+func renderSvgIfPossible(dotPath string) {
+	dotBinary, err := exec.LookPath("dot")
+	if err != nil {
+		return
+	}
+	svgPath := strings.TrimSuffix(dotPath, ".dot") + ".svg"
+	cmd := exec.Command(dotBinary, "-Tsvg", dotPath, "-o", svgPath)
+	if err := ExecuteCommand(cmd); err != nil {
+		fmt.Fprintf(os.Stderr, "Error rendering svg: %v\n", err)
+		return
+	}
+	fmt.Println("Rendered", svgPath)
+}
+
+func init() {
+	birCmd.AddCommand(birCfgCmd)
+	birCfgCmd.Flags().String("function", "", "Name of the function to export")
+	birCfgCmd.Flags().StringP("output", "o", "", "Path to the generated DOT file (defaults to <function>.dot)")
+	viper.BindPFlag("cfg_function", birCfgCmd.Flags().Lookup("function"))
+	viper.BindPFlag("cfg_output", birCfgCmd.Flags().Lookup("output"))
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func readBirDump(t *testing.T) []BirFunction {
+	content, err := os.ReadFile("../testData/BirDump/main.bir")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return ParseBir(string(content))
+}
+
+func TestParseBir(t *testing.T) {
+	functions := readBirDump(t)
+
+	expectedNames := []string{"main", "foo"}
+	if !stringSlicesEqual(BirFunctionNames(functions), expectedNames) {
+		t.Fatalf("Expected functions to be %v, but got %v", expectedNames, BirFunctionNames(functions))
+	}
+
+	main := functions[0]
+	if len(main.Blocks) != 6 {
+		t.Errorf("Expected main to have 6 basic blocks, but got %d", len(main.Blocks))
+	}
+	if len(main.Locals) != 5 {
+		t.Errorf("Expected main to have 5 locals, but got %d", len(main.Locals))
+	}
+	expectedEntry := BirErrorEntry{TrapBB: "bb2", EndBB: "bb2", TargetBB: "bb5"}
+	if len(main.ErrorTable) != 1 || main.ErrorTable[0] != expectedEntry {
+		t.Errorf("Expected error table to be [%v], but got %v", expectedEntry, main.ErrorTable)
+	}
+}
+
+func TestCfgEdges(t *testing.T) {
+	functions := readBirDump(t)
+
+	testCases := []struct {
+		function string
+		expected []CfgEdge
+	}{
+		{"main", []CfgEdge{
+			{"bb0", "bb1", GotoEdge},
+			{"bb1", "bb2", TrueEdge},
+			{"bb1", "bb3", FalseEdge},
+			{"bb2", "bb3", ReturnEdge},
+			{"bb2", "bb5", ErrorEdge},
+			{"bb3", "bb4", GotoEdge},
+			{"bb5", panicNode, PanicEdge},
+		}},
+		{"foo", []CfgEdge{
+			{"bb0", panicNode, PanicEdge},
+		}},
+	}
+
+	for _, tc := range testCases {
+		function, ok := FindBirFunction(functions, tc.function)
+		if !ok {
+			t.Fatalf("Expected to find function %s", tc.function)
+		}
+		actual := CfgEdges(function)
+		if len(actual) != len(tc.expected) {
+			t.Fatalf("Expected edges of %s to be %v, but got %v", tc.function, tc.expected, actual)
+		}
+		for i := range actual {
+			if actual[i] != tc.expected[i] {
+				t.Errorf("Expected edges of %s to be %v, but got %v", tc.function, tc.expected, actual)
+				break
+			}
+		}
+	}
+}
+
+func TestCfgToDot(t *testing.T) {
+	functions := readBirDump(t)
+	main, _ := FindBirFunction(functions, "main")
+
+	dot := CfgToDot(main)
+	expectedLines := []string{
+		"digraph \"main\" {",
+		"bb0 [label=\"bb0\\l%1 = ConstLoad 10;\\lGOTO bb1;\\l\"];",
+		"bb2 -> bb5 [label=\"error\", style=dashed, color=red];",
+	}
+	for _, line := range expectedLines {
+		if !strings.Contains(dot, line) {
+			t.Errorf("Expected dot output to contain %s, but got %s", line, dot)
+		}
+	}
+}
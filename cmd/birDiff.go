@@ -0,0 +1,286 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Number of unchanged lines shown around each change
+const diffContext = 3
+
+var birTempPattern = regexp.MustCompile(`%\d+\b`)
+
+var birDiffCmd = &cobra.Command{
+	Use:   "diff <path>",
+	Short: "Show the function-by-function BIR difference between two toolchains or source revisions",
+	Long: `Show the function-by-function BIR difference between two toolchains or source revisions.
+Toolchains are given as the name of a registered toolchain, as <sourcePath>[@<version>] or as a revision of the
+configured checkout, which is built and cached like --rev; omitted parts default to the configured values.
+Renumbering of temporaries and basic blocks is ignored.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("Please provide the path to ballerina source/project")
+			os.Exit(1)
+		}
+		base, err := ResolveToolchain(viper.GetString("diff_base"))
+		ConsumeError(err)
+		head, err := ResolveToolchain(viper.GetString("diff_head"))
+		ConsumeError(err)
+		targetPath := ResolveTarget(args[0])
+		baseDump, err := DumpBir(base, targetPath)
+		ConsumeError(err)
+		headDump, err := DumpBir(head, targetPath)
+		ConsumeError(err)
+		diff := DiffBir(ParseBir(baseDump), ParseBir(headDump))
+		if diff == "" {
+			fmt.Printf("No BIR differences between %s and %s\n", base, head)
+			return
+		}
+		fmt.Printf("--- %s\n+++ %s\n", base, head)
+		fmt.Print(diff)
+	},
+}
+
+// DiffBir returns a function-by-function diff of the given BIR, ignoring renumbering of temporaries and basic blocks
+func DiffBir(base, head []BirFunction) string {
+	baseFunctions := normalizedBirFunctions(base)
+	headFunctions := normalizedBirFunctions(head)
+	var sb strings.Builder
+	for _, name := range orderedFunctionKeys(base, head) {
+		baseLines, inBase := baseFunctions[name]
+		headLines, inHead := headFunctions[name]
+		switch {
+		case !inHead:
+			fmt.Fprintf(&sb, "=== function %s (removed)\n", name)
+		case !inBase:
+			fmt.Fprintf(&sb, "=== function %s (added)\n", name)
+		default:
+			if hunks := diffLines(baseLines, headLines); hunks != "" {
+				fmt.Fprintf(&sb, "=== function %s\n%s", name, hunks)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// orderedFunctionKeys lists the functions of both dumps, in the order they appear in base followed by the ones only in head
+func orderedFunctionKeys(base, head []BirFunction) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, functions := range [][]BirFunction{base, head} {
+		for _, key := range birFunctionKeys(functions) {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// birFunctionKeys gives each function an unique key, disambiguating functions with the same name by their position
+func birFunctionKeys(functions []BirFunction) []string {
+	keys := make([]string, len(functions))
+	counts := make(map[string]int)
+	for i, function := range functions {
+		counts[function.Name]++
+		keys[i] = function.Name
+		if counts[function.Name] > 1 {
+			keys[i] = fmt.Sprintf("%s#%d", function.Name, counts[function.Name])
+		}
+	}
+	return keys
+}
+
+func normalizedBirFunctions(functions []BirFunction) map[string][]string {
+	normalized := make(map[string][]string, len(functions))
+	keys := birFunctionKeys(functions)
+	for i, function := range functions {
+		normalized[keys[i]] = NormalizeBirFunction(function)
+	}
+	return normalized
+}
+
+// NormalizeBirFunction renders the function as lines where temporaries and basic blocks are renumbered in order of
+// first appearance
+func NormalizeBirFunction(function BirFunction) []string {
+	lines := []string{function.Header}
+	lines = append(lines, function.Locals...)
+	for _, block := range function.Blocks {
+		lines = append(lines, block.Id+" {")
+		for _, instruction := range block.Instructions {
+			lines = append(lines, "    "+instruction)
+		}
+		lines = append(lines, "}")
+	}
+	for _, entry := range function.ErrorTable {
+		lines = append(lines, fmt.Sprintf("error %s %s -> %s", entry.TrapBB, entry.EndBB, entry.TargetBB))
+	}
+	temps := renumberer("%t")
+	blocks := renumberer("bb")
+	for i, line := range lines {
+		line = birTempPattern.ReplaceAllStringFunc(line, temps)
+		lines[i] = birBlockRefPattern.ReplaceAllStringFunc(line, blocks)
+	}
+	return lines
+}
+
+func renumberer(prefix string) func(string) string {
+	ids := make(map[string]string)
+	return func(id string) string {
+		if renamed, ok := ids[id]; ok {
+			return renamed
+		}
+		renamed := fmt.Sprintf("%s%d", prefix, len(ids))
+		ids[id] = renamed
+		return renamed
+	}
+}
+
+// diffLines returns an unified style diff of the lines, or an empty string if they are equal
+func diffLines(a, b []string) string {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	var ops []string
+	for _, line := range a[:prefix] {
+		ops = append(ops, "  "+line)
+	}
+	ops = appendDiffOps(ops, a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, "  "+line)
+	}
+	return withContext(ops)
+}
+
+// appendDiffOps appends the edit script from a to b, using Hirschberg's algorithm so that memory stays linear in the
+// size of the functions
+func appendDiffOps(ops, a, b []string) []string {
+	switch {
+	case len(a) == 0:
+		for _, line := range b {
+			ops = append(ops, "+ "+line)
+		}
+		return ops
+	case len(b) == 0:
+		for _, line := range a {
+			ops = append(ops, "- "+line)
+		}
+		return ops
+	case len(a) == 1:
+		for k, line := range b {
+			if line == a[0] {
+				ops = appendDiffOps(ops, nil, b[:k])
+				ops = append(ops, "  "+line)
+				return appendDiffOps(ops, nil, b[k+1:])
+			}
+		}
+		ops = append(ops, "- "+a[0])
+		return appendDiffOps(ops, nil, b)
+	}
+	mid := len(a) / 2
+	prefixLengths := lcsPrefixLengths(a[:mid], b)
+	suffixLengths := lcsSuffixLengths(a[mid:], b)
+	split := 0
+	for k := range prefixLengths {
+		if prefixLengths[k]+suffixLengths[k] > prefixLengths[split]+suffixLengths[split] {
+			split = k
+		}
+	}
+	ops = appendDiffOps(ops, a[:mid], b[:split])
+	return appendDiffOps(ops, a[mid:], b[split:])
+}
+
+// lcsPrefixLengths returns the length of the longest common subsequence of a and b[:j] for each j
+func lcsPrefixLengths(a, b []string) []int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for i := range a {
+		for j := 1; j <= len(b); j++ {
+			switch {
+			case a[i] == b[j-1]:
+				current[j] = previous[j-1] + 1
+			case previous[j] >= current[j-1]:
+				current[j] = previous[j]
+			default:
+				current[j] = current[j-1]
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous
+}
+
+// lcsSuffixLengths returns the length of the longest common subsequence of a and b[j:] for each j
+func lcsSuffixLengths(a, b []string) []int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				current[j] = previous[j+1] + 1
+			case previous[j] >= current[j+1]:
+				current[j] = previous[j]
+			default:
+				current[j] = current[j+1]
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous
+}
+
+// withContext keeps only the changed lines and diffContext unchanged lines around them
+func withContext(ops []string) string {
+	keep := make([]bool, len(ops))
+	changed := false
+	for i, op := range ops {
+		if op[0] == ' ' {
+			continue
+		}
+		changed = true
+		for k := i - diffContext; k <= i+diffContext; k++ {
+			if k >= 0 && k < len(ops) {
+				keep[k] = true
+			}
+		}
+	}
+	if !changed {
+		return ""
+	}
+	var sb strings.Builder
+	for i, op := range ops {
+		if !keep[i] {
+			continue
+		}
+		if i > 0 && !keep[i-1] {
+			sb.WriteString("  ...\n")
+		}
+		sb.WriteString(op + "\n")
+	}
+	return sb.String()
+}
+
+func init() {
+	birCmd.AddCommand(birDiffCmd)
+	birDiffCmd.Flags().String("base", "", "Toolchain to compare against, as a toolchain name, <sourcePath>[@<version>] or a revision")
+	birDiffCmd.Flags().String("head", "", "Toolchain with the changes, as a toolchain name, <sourcePath>[@<version>] or a revision")
+	viper.BindPFlag("diff_base", birDiffCmd.Flags().Lookup("base"))
+	viper.BindPFlag("diff_head", birDiffCmd.Flags().Lookup("head"))
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeBirFunctionIgnoresRenumbering(t *testing.T) {
+	base := BirFunction{
+		Name:   "main",
+		Header: "public function main() -> () {",
+		Locals: []string{"%0(RETURN) ();", "%4(TEMP) int;"},
+		Blocks: []BirBasicBlock{
+			{"bb0", []string{"%4 = ConstLoad 1;", "GOTO bb3;"}},
+			{"bb3", []string{"return;"}},
+		},
+	}
+	head := BirFunction{
+		Name:   "main",
+		Header: "public function main() -> () {",
+		Locals: []string{"%0(RETURN) ();", "%7(TEMP) int;"},
+		Blocks: []BirBasicBlock{
+			{"bb0", []string{"%7 = ConstLoad 1;", "GOTO bb1;"}},
+			{"bb1", []string{"return;"}},
+		},
+	}
+
+	if diff := DiffBir([]BirFunction{base}, []BirFunction{head}); diff != "" {
+		t.Errorf("Expected no difference, but got %s", diff)
+	}
+}
+
+func TestDiffBir(t *testing.T) {
+	base := []BirFunction{
+		{Name: "main", Header: "function main() -> () {", Blocks: []BirBasicBlock{{"bb0", []string{"%1 = ConstLoad 1;", "return;"}}}},
+		{Name: "foo", Header: "function foo() -> () {", Blocks: []BirBasicBlock{{"bb0", []string{"return;"}}}},
+	}
+	head := []BirFunction{
+		{Name: "main", Header: "function main() -> () {", Blocks: []BirBasicBlock{{"bb0", []string{"%1 = ConstLoad 2;", "return;"}}}},
+		{Name: "bar", Header: "function bar() -> () {", Blocks: []BirBasicBlock{{"bb0", []string{"return;"}}}},
+	}
+
+	diff := DiffBir(base, head)
+	expectedLines := []string{
+		"=== function main\n",
+		"- " + "    %t0 = ConstLoad 1;\n",
+		"+ " + "    %t0 = ConstLoad 2;\n",
+		"=== function foo (removed)\n",
+		"=== function bar (added)\n",
+	}
+	for _, line := range expectedLines {
+		if !strings.Contains(diff, line) {
+			t.Errorf("Expected diff to contain %q, but got %s", line, diff)
+		}
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	testCases := []struct {
+		a        []string
+		b        []string
+		expected string
+	}{
+		{[]string{"x", "y"}, []string{"x", "y"}, ""},
+		{[]string{"x", "z"}, []string{"x", "y", "z"}, "  x\n+ y\n  z\n"},
+		{[]string{"x", "y", "z"}, []string{"x", "z"}, "  x\n- y\n  z\n"},
+		{[]string{"x", "y", "z"}, []string{"x", "w", "z"}, "  x\n- y\n+ w\n  z\n"},
+		{[]string{"a", "b", "c", "d"}, []string{"b", "x", "d", "e"}, "- a\n  b\n- c\n+ x\n  d\n+ e\n"},
+		{nil, []string{"x"}, "+ x\n"},
+	}
+
+	for _, tc := range testCases {
+		if actual := diffLines(tc.a, tc.b); actual != tc.expected {
+			t.Errorf("Expected diff of %v and %v to be %q but got %q", tc.a, tc.b, tc.expected, actual)
+		}
+	}
+}
+
+func TestAppendDiffOpsKeepsLongestCommonSubsequence(t *testing.T) {
+	a := strings.Split("a b c a b b a c b a", " ")
+	b := strings.Split("c b a b a c c a b", " ")
+
+	var kept, removed, added []string
+	for _, op := range appendDiffOps(nil, a, b) {
+		switch op[0] {
+		case ' ':
+			kept = append(kept, op[2:])
+			removed = append(removed, op[2:])
+			added = append(added, op[2:])
+		case '-':
+			removed = append(removed, op[2:])
+		case '+':
+			added = append(added, op[2:])
+		}
+	}
+	if !stringSlicesEqual(removed, a) || !stringSlicesEqual(added, b) {
+		t.Errorf("Expected the edit script to turn %v into %v but got %v and %v", a, b, removed, added)
+	}
+	if lcs := lcsPrefixLengths(a, b)[len(b)]; len(kept) != lcs {
+		t.Errorf("Expected %d unchanged lines but got %d", lcs, len(kept))
+	}
+}
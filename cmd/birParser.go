@@ -0,0 +1,129 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+)
+
+type BirFunction struct {
+	Name       string
+	Header     string
+	Locals     []string
+	Blocks     []BirBasicBlock
+	ErrorTable []BirErrorEntry
+}
+
+type BirBasicBlock struct {
+	Id           string
+	Instructions []string
+}
+
+type BirErrorEntry struct {
+	TrapBB   string
+	EndBB    string
+	TargetBB string
+}
+
+var (
+	birBlockHeaderPattern = regexp.MustCompile(`^(bb\d+)\s*\{$`)
+	birBlockRefPattern    = regexp.MustCompile(`\bbb\d+\b`)
+)
+
+// ParseBir parses the textual BIR emitted by `bal build --dump-bir` into functions. Anything that is not part of
+// a function body (compiler logs, module and global declarations) is ignored.
+func ParseBir(dump string) []BirFunction {
+	var functions []BirFunction
+	var current *BirFunction
+	var block *BirBasicBlock
+	inErrorTable := false
+	scanner := bufio.NewScanner(strings.NewReader(dump))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		switch {
+		case current == nil:
+			if name, ok := birFunctionName(line); ok {
+				current = &BirFunction{Name: name, Header: line}
+			}
+		case block != nil:
+			if line == "}" {
+				current.Blocks = append(current.Blocks, *block)
+				block = nil
+			} else {
+				block.Instructions = append(block.Instructions, line)
+			}
+		case line == "}":
+			functions = append(functions, *current)
+			current = nil
+			inErrorTable = false
+		case strings.HasPrefix(line, "Error Table"):
+			inErrorTable = true
+		case inErrorTable:
+			if entry, ok := parseBirErrorEntry(line); ok {
+				current.ErrorTable = append(current.ErrorTable, entry)
+			}
+		default:
+			if match := birBlockHeaderPattern.FindStringSubmatch(line); match != nil {
+				block = &BirBasicBlock{Id: match[1]}
+			} else {
+				current.Locals = append(current.Locals, line)
+			}
+		}
+	}
+	return functions
+}
+
+func FindBirFunction(functions []BirFunction, name string) (BirFunction, bool) {
+	for _, function := range functions {
+		if function.Name == name {
+			return function, true
+		}
+	}
+	return BirFunction{}, false
+}
+
+func BirFunctionNames(functions []BirFunction) []string {
+	names := make([]string, len(functions))
+	for i, function := range functions {
+		names[i] = function.Name
+	}
+	return names
+}
+
+// birFunctionName recognises function headers such as `public function main() -> () {` and returns the function name
+func birFunctionName(line string) (string, bool) {
+	if !strings.HasSuffix(line, "{") || birBlockHeaderPattern.MatchString(line) {
+		return "", false
+	}
+	paren := strings.Index(line, "(")
+	if paren <= 0 {
+		return "", false
+	}
+	words := strings.Fields(line[:paren])
+	if len(words) == 0 {
+		return "", false
+	}
+	name := words[len(words)-1]
+	if name == "function" && len(words) > 1 {
+		name = words[len(words)-2]
+	}
+	return name, true
+}
+
+// parseBirErrorEntry parses an error table row of the form `trapBB endBB errorOp targetBB;`
+func parseBirErrorEntry(line string) (BirErrorEntry, bool) {
+	refs := birBlockRefPattern.FindAllString(line, -1)
+	if len(refs) < 3 {
+		return BirErrorEntry{}, false
+	}
+	return BirErrorEntry{TrapBB: refs[0], EndBB: refs[1], TargetBB: refs[len(refs)-1]}, true
+}
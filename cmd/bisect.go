@@ -0,0 +1,230 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type BisectPredicate string
+
+const (
+	// The reproducer fails to compile
+	CompileFails BisectPredicate = "compile-fail"
+	// The compiler crashes while compiling the reproducer
+	CompilerCrash BisectPredicate = "crash"
+	// The output of running the reproducer differs from the expected output
+	OutputDiffers BisectPredicate = "output"
+	// The average execution time of the reproducer is above the threshold
+	BenchmarkRegression BisectPredicate = "benchmark"
+)
+
+// Upper bound on the number of bisect steps, git bisect needs log2 of the number of commits
+const maxBisectSteps = 64
+
+// Messages printed by the compiler when it crashes instead of reporting diagnostics
+var compilerCrashMarkers = []string{
+	"oh no, something really went wrong",
+	"Exception in thread \"main\"",
+}
+
+var bisectCmd = &cobra.Command{
+	Use:   "bisect <path> --good <rev> --bad <rev> [-- bal-flags... [-- program-args...]]",
+	Short: "Find the ballerina-lang commit that broke a reproducer",
+	Long: `Find the ballerina-lang commit that broke a reproducer.
+Drives git bisect on the source checkout, building the toolchain of each step (cached by commit) and checking the
+reproducer with the chosen predicate:
+  compile-fail  compiling the reproducer fails
+  crash         the compiler crashes while compiling the reproducer
+  output        the output of running the reproducer differs from --expected-output
+  benchmark     the average execution time of the reproducer is above --threshold
+Commits that can't be built are skipped.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		args, passThrough := SplitPassThroughArgs(cmd, args)
+		if len(args) != 1 {
+			fmt.Println("Please provide the path to the ballerina reproducer")
+			os.Exit(1)
+		}
+		good, bad := viper.GetString("bisect_good"), viper.GetString("bisect_bad")
+		if good == "" || bad == "" {
+			fmt.Println("Please provide the good and bad revisions using --good and --bad")
+			os.Exit(1)
+		}
+		predicate := BisectPredicate(viper.GetString("bisect_predicate"))
+		ConsumeError(validatePredicate(predicate))
+		checkout := SelectedToolchain()
+		if checkout.Kind != CheckoutToolchain {
+			ConsumeError(fmt.Errorf("bisect requires a checkout toolchain, but %s is a %s", checkout.Name, checkout.Kind))
+		}
+		targetPath := ResolveTarget(args[0])
+		firstBad, err := Bisect(checkout.Path, good, bad, func(toolchain Toolchain) (bool, error) {
+			return evaluatePredicate(predicate, toolchain, targetPath, passThrough)
+		})
+		ConsumeError(err)
+		fmt.Println("First bad commit:", firstBad)
+	},
+}
+
+func validatePredicate(predicate BisectPredicate) error {
+	switch predicate {
+	case CompileFails, CompilerCrash:
+		return nil
+	case OutputDiffers:
+		if viper.GetString("bisect_expected_output") == "" {
+			return fmt.Errorf("the output predicate requires --expected-output")
+		}
+		return nil
+	case BenchmarkRegression:
+		if viper.GetDuration("bisect_threshold") <= 0 {
+			return fmt.Errorf("the benchmark predicate requires --threshold")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown predicate %s, expected one of compile-fail, crash, output or benchmark", predicate)
+	}
+}
+
+// Bisect runs git bisect on the checkout between the good and bad revisions. isBad decides whether the toolchain
+// of a commit is bad, and an error from it skips the commit. It returns the first bad commit. The bisection doesn't
+// check out the commits, which are built in worktrees, so the working tree of the checkout is left alone.
+func Bisect(sourcePath, good, bad string, isBad func(Toolchain) (bool, error)) (string, error) {
+	defer gitBisect(sourcePath, "reset")
+	if _, err := gitBisect(sourcePath, "start", "--no-checkout", bad, good); err != nil {
+		return "", err
+	}
+	for step := 0; step < maxBisectSteps; step++ {
+		commit, err := ResolveRevision(sourcePath, "BISECT_HEAD")
+		if err != nil {
+			return "", err
+		}
+		verdict := "good"
+		toolchain, err := RevisionToolchain(sourcePath, commit)
+		if err == nil {
+			var commitIsBad bool
+			commitIsBad, err = isBad(toolchain)
+			if commitIsBad {
+				verdict = "bad"
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping %s: %v\n", shortCommit(commit), err)
+			verdict = "skip"
+		}
+		fmt.Printf("Commit %s is %s\n", shortCommit(commit), verdict)
+		out, err := gitBisect(sourcePath, verdict)
+		if err != nil {
+			return "", err
+		}
+		if firstBad, ok := firstBadCommit(out); ok {
+			return firstBad, nil
+		}
+		if strings.Contains(out, "only 'skip'ped commits left") {
+			return "", fmt.Errorf("unable to find the first bad commit since commits were skipped:\n%s", out)
+		}
+	}
+	return "", fmt.Errorf("bisect didn't finish after %d steps", maxBisectSteps)
+}
+
+func gitBisect(sourcePath string, args ...string) (string, error) {
+	out, err := exec.Command("git", append([]string{"-C", sourcePath, "bisect"}, args...)...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git bisect %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return string(out), nil
+}
+
+// firstBadCommit extracts the commit from the `<commit> is the first bad commit` message of git bisect
+func firstBadCommit(out string) (string, bool) {
+	for _, line := range strings.Split(out, "\n") {
+		if commit, found := strings.CutSuffix(strings.TrimSpace(line), " is the first bad commit"); found {
+			return commit, true
+		}
+	}
+	return "", false
+}
+
+func evaluatePredicate(predicate BisectPredicate, toolchain Toolchain, path string, passThrough []string) (bool, error) {
+	balFlags, programArgs := SplitRunArgs(passThrough)
+	switch predicate {
+	case CompileFails, CompilerCrash:
+		command, err := CreateCommand(toolchain, path, Build, DebugOptions{}, balFlags...)
+		if err != nil {
+			return false, err
+		}
+		out, err := command.CombinedOutput()
+		if predicate == CompileFails {
+			return err != nil, nil
+		}
+		return isCompilerCrash(string(out)), nil
+	case OutputDiffers:
+		matches, _, err := OutputMatches(toolchain, path, viper.GetString("bisect_expected_output"), passThrough)
+		return !matches, err
+	default:
+		if err := CompileTarget(toolchain, path, balFlags...); err != nil {
+			return false, err
+		}
+		jarPath, err := FindOutputJar(path, balFlags)
+		if err != nil {
+			return false, err
+		}
+		command := CreateJarRunCommand(jarPath, programArgs...)
+		result, err := BenchmarkCommand(&command)
+		if err != nil {
+			return false, err
+		}
+		PrettyPrintBenchmarkResult(result)
+		return result.AvgTime > viper.GetDuration("bisect_threshold"), nil
+	}
+}
+
+func isCompilerCrash(output string) bool {
+	for _, marker := range compilerCrashMarkers {
+		if strings.Contains(output, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// OutputMatches runs the target and compares its standard output with the content of the expected output file,
+// ignoring trailing whitespace. It also returns the actual output.
+func OutputMatches(toolchain Toolchain, path, expectedOutputPath string, passThrough []string) (bool, string, error) {
+	expected, err := os.ReadFile(expectedOutputPath)
+	if err != nil {
+		return false, "", err
+	}
+	command, err := CreateCommand(toolchain, path, Run, DebugOptions{}, passThrough...)
+	if err != nil {
+		return false, "", err
+	}
+	var stdout bytes.Buffer
+	command.Stdout = &stdout
+	// A failing program is compared by its output like any other
+	command.Run()
+	actual := stdout.String()
+	return strings.TrimRight(actual, " \t\r\n") == strings.TrimRight(string(expected), " \t\r\n"), actual, nil
+}
+
+func init() {
+	rootCmd.AddCommand(bisectCmd)
+	bisectCmd.Flags().String("good", "", "Revision where the reproducer behaves correctly")
+	bisectCmd.Flags().String("bad", "", "Revision where the reproducer misbehaves")
+	bisectCmd.Flags().String("predicate", string(CompileFails), "How to decide whether a commit is bad: compile-fail, crash, output or benchmark")
+	bisectCmd.Flags().String("expected-output", "", "File with the expected output of the reproducer, for the output predicate")
+	bisectCmd.Flags().Duration("threshold", 0, "Maximum average execution time, for the benchmark predicate")
+	viper.BindPFlag("bisect_good", bisectCmd.Flags().Lookup("good"))
+	viper.BindPFlag("bisect_bad", bisectCmd.Flags().Lookup("bad"))
+	viper.BindPFlag("bisect_predicate", bisectCmd.Flags().Lookup("predicate"))
+	viper.BindPFlag("bisect_expected_output", bisectCmd.Flags().Lookup("expected-output"))
+	viper.BindPFlag("bisect_threshold", bisectCmd.Flags().Lookup("threshold"))
+}
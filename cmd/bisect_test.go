@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBisect(t *testing.T) {
+	repo := initTestRepo(t)
+	setTestConfig(t, "cacheDir", t.TempDir())
+
+	commits := make(map[string]bool)
+	for i := 0; i < 6; i++ {
+		writeTestFile(t, filepath.Join(repo, "file.txt"), strings.Repeat("x", i))
+		exec.Command("git", "-C", repo, "add", ".").Run()
+		commit := exec.Command("git", "-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com",
+			"commit", "-q", "-m", "change")
+		if out, err := commit.CombinedOutput(); err != nil {
+			t.Fatalf("Unexpected error: %v %s", err, out)
+		}
+		hash, err := ResolveRevision(repo, "HEAD")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		// Commits after the third one are bad
+		commits[hash] = i >= 3
+		if err := WriteBuildInfo(revisionBuildInfoPath(hash), BuildInfo{Commit: hash, Version: "1.0.0"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		writeTestFile(t, filepath.Join(revisionsDir(), hash, "jballerina-tools-1.0.0", "bin", "bal"), "")
+	}
+	var good, expected string
+	for hash, bad := range commits {
+		if !bad {
+			continue
+		}
+		parent, _ := ResolveRevision(repo, hash+"^")
+		if !commits[parent] {
+			good, expected = parent, hash
+		}
+	}
+
+	head, _ := ResolveRevision(repo, "HEAD")
+	// Uncommitted changes are left alone since the commits are never checked out
+	writeTestFile(t, filepath.Join(repo, "file.txt"), "uncommitted")
+
+	firstBad, err := Bisect(repo, good, "HEAD", func(toolchain Toolchain) (bool, error) {
+		commit := filepath.Base(filepath.Dir(toolchain.Path))
+		return commits[commit], nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if firstBad != expected {
+		t.Errorf("Expected first bad commit to be %s, but got %s", expected, firstBad)
+	}
+	if after, _ := ResolveRevision(repo, "HEAD"); after != head {
+		t.Errorf("Expected HEAD to stay at %s, but got %s", head, after)
+	}
+	if content, err := os.ReadFile(filepath.Join(repo, "file.txt")); err != nil || string(content) != "uncommitted" {
+		t.Errorf("Expected the working tree to be left alone, but got %q, %v", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, ".git", "BISECT_START")); !os.IsNotExist(err) {
+		t.Errorf("Expected the bisection to be reset, but got %v", err)
+	}
+}
+
+func TestIsCompilerCrash(t *testing.T) {
+	crash := "ballerina: oh no, something really went wrong. Bad. Sad.\n"
+	if !isCompilerCrash(crash) {
+		t.Errorf("Expected %q to be detected as a crash", crash)
+	}
+	diagnostic := "ERROR [main.bal:(1:1,1:2)] undefined symbol 'x'\n"
+	if isCompilerCrash(diagnostic) {
+		t.Errorf("Expected %q not to be detected as a crash", diagnostic)
+	}
+}
@@ -22,29 +22,18 @@ THE SOFTWARE.
 package cmd
 
 import (
-	"fmt"
-	"os"
-
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var buildCmd = &cobra.Command{
-	Use:   "build [path]",
+	Use:   "build [path] [-- bal-flags...]",
 	Short: "Build project or file",
 	Run: func(cmd *cobra.Command, args []string) {
-		var targetPath string
-		if len(args) > 0 {
-			targetPath = args[0]
-		} else {
-			if viper.GetBool("file_comp") {
-				fmt.Println("Please provide a file to compile")
-				os.Exit(1)
-			}
-			targetPath = CurrentWorkingDir()
-		}
-		command, err := CreateCommand(viper.GetString("sourcePath"), viper.GetString("version"), targetPath, Build,
-			viper.GetBool("remote_comp"))
+		args, passThrough := SplitPassThroughArgs(cmd, args)
+		targetPath := TargetFromArgs(args)
+		command, err := CreateCommand(CurrentToolchain(), targetPath, Build,
+			NewDebugOptions(viper.GetBool("remote_comp"), false), passThrough...)
 		ConsumeError(err)
 		if viper.GetBool("bench_comp") {
 			result, err := BenchmarkCommand(&command)
@@ -59,12 +48,11 @@ var buildCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(buildCmd)
+	addBuildFlagsFlag(buildCmd.Flags())
 
-	buildCmd.Flags().BoolP("file", "f", false, "Run the given file")
 	buildCmd.Flags().BoolP("remote", "r", false, "Remote debug the compiler")
 	buildCmd.Flags().BoolP("benchmark", "b", false, "Benchmark the compiler")
 
-	viper.BindPFlag("file_comp", buildCmd.Flags().Lookup("file"))
 	viper.BindPFlag("remote_comp", buildCmd.Flags().Lookup("remote"))
 	viper.BindPFlag("bench_comp", buildCmd.Flags().Lookup("benchmark"))
 }
@@ -0,0 +1,85 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// BuildInfo describes the checkout a distribution was built from
+type BuildInfo struct {
+	Commit  string    `json:"commit"`
+	Dirty   bool      `json:"dirty"`
+	Version string    `json:"version"`
+	BuiltAt time.Time `json:"builtAt"`
+}
+
+// CacheDir is where the tool keeps toolchains and logs, configurable with the cacheDir key
+func CacheDir() string {
+	if dir := viper.GetString("cacheDir"); dir != "" {
+		return expandHome(dir)
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "jBalCompTools")
+}
+
+// cacheKey names the state kept in the cache directory about a path outside of it by the hash of its absolute path
+func cacheKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	hash := sha256.Sum256([]byte(path))
+	return hex.EncodeToString(hash[:8])
+}
+
+func checkoutBuildInfoPath(sourcePath, version string) string {
+	return filepath.Join(extractedDistributionsDir(sourcePath), extractedDistributionPrefix+version+".build.json")
+}
+
+// CurrentBuildInfo captures the state of the checkout that is about to be, or has just been, built
+func CurrentBuildInfo(sourcePath, version string) BuildInfo {
+	info := BuildInfo{Version: version, BuiltAt: time.Now()}
+	if out, err := exec.Command("git", "-C", sourcePath, "rev-parse", "HEAD").Output(); err == nil {
+		info.Commit = strings.TrimSpace(string(out))
+	}
+	if out, err := exec.Command("git", "-C", sourcePath, "status", "--porcelain", "--untracked-files=no").Output(); err == nil {
+		info.Dirty = len(strings.TrimSpace(string(out))) > 0
+	}
+	return info
+}
+
+func ReadBuildInfo(path string) (BuildInfo, error) {
+	var info BuildInfo
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return info, err
+	}
+	err = json.Unmarshal(content, &info)
+	return info, err
+}
+
+func WriteBuildInfo(path string, info BuildInfo) error {
+	content, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
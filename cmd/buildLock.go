@@ -0,0 +1,73 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// buildLockPath is the lock file of the checkout in the cache directory
+func buildLockPath(sourcePath string) string {
+	return filepath.Join(CacheDir(), "locks", cacheKey(sourcePath)+".lock")
+}
+
+// AcquireBuildLock takes the rebuild lock of the checkout, waiting while another process holds it. The lock is an
+// advisory lock of the OS, so it is released when the process holding it exits. The returned function releases it.
+func AcquireBuildLock(sourcePath string) (func(), error) {
+	path := buildLockPath(sourcePath)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	err = lockFile(file, false)
+	if errors.Is(err, errLockHeld) {
+		if pid, ok := lockOwner(file); ok {
+			fmt.Fprintf(os.Stderr, "Waiting for rebuild started by pid %d\n", pid)
+		} else {
+			fmt.Fprintln(os.Stderr, "Waiting for another rebuild to finish")
+		}
+		err = lockFile(file, true)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	// The pid is only informational, for the message of the processes waiting for the lock
+	if err := file.Truncate(0); err == nil {
+		file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return func() {
+		file.Truncate(0)
+		unlockFile(file)
+		file.Close()
+	}, nil
+}
+
+// lockOwner reads the pid of the process holding the lock. It fails while the owner is still writing it.
+func lockOwner(file *os.File) (int, bool) {
+	content := make([]byte, 32)
+	n, _ := file.ReadAt(content, 0)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content[:n])))
+	return pid, err == nil
+}
+
+// withBuildLock runs build while holding the rebuild lock of the checkout
+func withBuildLock(sourcePath string, build func() error) error {
+	release, err := AcquireBuildLock(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return build()
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestBuildLockWaitsForRelease(t *testing.T) {
+	sourcePath := t.TempDir()
+	setTestConfig(t, "cacheDir", t.TempDir())
+	release, err := AcquireBuildLock(sourcePath)
+	if err != nil {
+		t.Fatalf("Expected to acquire the lock but got %v", err)
+	}
+	acquired := make(chan struct{})
+	go func() {
+		releaseSecond, err := AcquireBuildLock(sourcePath)
+		if err == nil {
+			releaseSecond()
+		}
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatalf("Expected the second lock to wait while the first is held")
+	case <-time.After(time.Second):
+	}
+	release()
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the second lock to be acquired after the first was released")
+	}
+	if !strings.HasPrefix(buildLockPath(sourcePath), viper.GetString("cacheDir")) {
+		t.Errorf("Expected the lock file to be in the cache directory but got %s", buildLockPath(sourcePath))
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+//go:build windows
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32         = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = kernel32.NewProc("LockFileEx")
+	procUnlockFileEx = kernel32.NewProc("UnlockFileEx")
+)
+
+const (
+	lockfileFailImmediately = 0x1
+	lockfileExclusiveLock   = 0x2
+)
+
+// Returned by lockFile when it doesn't block and another process holds the lock (ERROR_LOCK_VIOLATION)
+var errLockHeld error = syscall.Errno(33)
+
+// lockRange is the byte range that is locked. Locks are mandatory on windows, so it is past the pid written in the
+// file to keep it readable by the processes waiting for the lock.
+func lockRange() *syscall.Overlapped {
+	return &syscall.Overlapped{OffsetHigh: 1}
+}
+
+func lockFile(file *os.File, block bool) error {
+	flags := uintptr(lockfileExclusiveLock)
+	if !block {
+		flags |= lockfileFailImmediately
+	}
+	r1, _, err := procLockFileEx.Call(file.Fd(), flags, 0, 1, 0, uintptr(unsafe.Pointer(lockRange())))
+	if r1 == 0 {
+		return err
+	}
+	return nil
+}
+
+func unlockFile(file *os.File) error {
+	r1, _, err := procUnlockFileEx.Call(file.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(lockRange())))
+	if r1 == 0 {
+		return err
+	}
+	return nil
+}
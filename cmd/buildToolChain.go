@@ -6,6 +6,9 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -13,14 +16,35 @@ import (
 var buildToolsCmd = &cobra.Command{
 	Use:   "buildTools",
 	Short: "Build jBallerina compiler",
+	Long: `Build jBallerina compiler.
+When --version is given the distribution is built as that version without modifying the checkout, so that
+toolchains of different versions can be built side by side from the same checkout.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		err := BuildCompiler(viper.GetString("sourcePath"), viper.GetString("flags"))
+		toolchain := CurrentToolchain()
+		if toolchain.Kind != CheckoutToolchain {
+			ConsumeError(fmt.Errorf("toolchain %s is a %s, only checkouts can be built", toolchain.Name, toolchain.Kind))
+		}
+		var version string
+		if cmd.Flags().Changed("version") {
+			version = versionOverride(toolchain.Path, toolchain.Version)
+		}
+		err := withBuildLock(toolchain.Path, func() error {
+			if err := BuildCompiler(toolchain.Path, viper.GetString("build.manualTasks"), version); err != nil {
+				return err
+			}
+			if version != "" {
+				if err := recordBuildVersion(toolchain.Path, version); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: unable to record the build version: %v\n", err)
+				}
+			}
+			return nil
+		})
 		ConsumeError(err)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(buildToolsCmd)
-	buildToolsCmd.Flags().String("flags", "buildTools -x check", "Flags to pass to the gradle wrapper")
-	viper.BindPFlag("flags", buildToolsCmd.Flags().Lookup("flags"))
+	buildToolsCmd.Flags().String("flags", defaultManualBuildTasks, "Flags to pass to the gradle wrapper")
+	viper.BindPFlag("build.manualTasks", buildToolsCmd.Flags().Lookup("flags"))
 }
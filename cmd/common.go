@@ -6,6 +6,7 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
@@ -13,66 +14,171 @@ import (
 	"strings"
 	"time"
 
-	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 type Command string
 
 const (
-	Run   Command = "run"
-	Build Command = "build"
-	Test  Command = "test"
+	Run     Command = "run"
+	Build   Command = "build"
+	Test    Command = "test"
+	Pack    Command = "pack"
+	Clean   Command = "clean"
+	Format  Command = "format"
+	Doc     Command = "doc"
+	Graph   Command = "graph"
+	New     Command = "new"
+	Add     Command = "add"
+	Bindgen Command = "bindgen"
 )
 
-func CreateJarRunCommand(jarPath string) exec.Cmd {
-	return *exec.Command("java", "-jar", jarPath)
+func CreateJarRunCommand(jarPath string, programArgs ...string) exec.Cmd {
+	args := []string{"-jar", jarPath}
+	args = append(args, programArgs...)
+	return *exec.Command("java", args...)
 }
 
-func CreateCommand(sourcePath, version, targetPath string, command Command, remoteDebug bool, args ...string) (exec.Cmd, error) {
-	ConsumeError(buildCompilerIfNeeded(sourcePath, version))
-	return CreateCommandInner(sourcePath, version, targetPath, command, remoteDebug, args...)
+// CreateJarDebugCommand runs the jar with a debug agent for the runtime, which only suspends it with debug.Suspend
+func CreateJarDebugCommand(jarPath string, debug DebugOptions, programArgs ...string) exec.Cmd {
+	args := []string{jdwpAgent(debug.RuntimePort, debug.Suspend), "-jar", jarPath}
+	args = append(args, programArgs...)
+	return *exec.Command("java", args...)
 }
 
-func CreateCommandInner(sourcePath, version, targetPath string, command Command, remoteDebug bool, args ...string) (exec.Cmd, error) {
-	balPath := BalPath(sourcePath, version)
+// CreateCommand builds the toolchain if needed and creates the bal command. Build failures are returned rather than
+// exiting so that callers such as bisect can recover from them.
+func CreateCommand(toolchain Toolchain, targetPath string, command Command, debug DebugOptions, args ...string) (exec.Cmd, error) {
+	if err := buildCompilerIfNeeded(toolchain); err != nil {
+		return exec.Cmd{}, err
+	}
+	recordToolchainUse(toolchain)
+	warnOnDistributionMismatch(toolchain, targetPath)
+	return CreateCommandInner(toolchain, targetPath, command, debug, args...)
+}
+
+func CreateCommandInner(toolchain Toolchain, targetPath string, command Command, debug DebugOptions, args ...string) (exec.Cmd, error) {
+	balPath := toolchain.BalPath()
 	switch command {
 	case Run:
-		return createRunCommand(balPath, targetPath, remoteDebug, args...), nil
+		return createRunCommand(balPath, targetPath, debug, args...), nil
 	case Build:
-		return createBuildCommand(balPath, targetPath, remoteDebug, args...), nil
+		return createBuildCommand(balPath, targetPath, debug, args...), nil
 	case Test:
-		return createTestCommand(balPath, targetPath, remoteDebug, args...), nil
+		return createTestCommand(balPath, targetPath, debug, args...), nil
+	case Pack, Clean, Format, Doc, Graph, New, Add, Bindgen:
+		return createToolCommand(balPath, targetPath, command, debug, args...), nil
 	default:
 		return exec.Cmd{}, fmt.Errorf("unknown command: %s", command)
 	}
 }
 
-func createBuildCommand(balPath, targetPath string, remoteDebug bool, extraArgs ...string) exec.Cmd {
-	args := []string{"build"}
+func createBuildCommand(balPath, targetPath string, debug DebugOptions, extraArgs ...string) exec.Cmd {
+	cmd := exec.Command(balPath, balArgs("build", targetPath, extraArgs)...)
+	if debug.Compiler {
+		cmd.Env = debug.compilerDebugEnv()
+	}
+	return *cmd
+}
+
+func createTestCommand(balPath, targetPath string, debug DebugOptions, extraArgs ...string) exec.Cmd {
+	return createExecCommand(balPath, targetPath, "test", debug, extraArgs...)
+}
+
+func createRunCommand(balPath, targetPath string, debug DebugOptions, extraArgs ...string) exec.Cmd {
+	balFlags, programArgs := SplitRunArgs(extraArgs)
+	if len(programArgs) > 0 {
+		extraArgs = append(append(append([]string{}, balFlags...), "--"), programArgs...)
+	}
+	return createExecCommand(balPath, targetPath, "run", debug, extraArgs...)
+}
+
+func createExecCommand(balPath, targetPath, runCommand string, debug DebugOptions, extraArgs ...string) exec.Cmd {
+	var args []string
+	if debug.Runtime {
+		args = append(args, debug.runtimeDebugArgs()...)
+	}
 	args = append(args, extraArgs...)
-	args = append(args, targetPath)
-	cmd := exec.Command(balPath, args...)
-	if remoteDebug {
-		cmd.Env = append(os.Environ(), "BAL_JAVA_DEBUG=5005")
+	cmd := exec.Command(balPath, balArgs(runCommand, targetPath, args)...)
+	if debug.Compiler {
+		cmd.Env = debug.compilerDebugEnv()
+	}
+	return *cmd
+}
+
+// createToolCommand creates commands that only run the compiler side of the toolchain
+func createToolCommand(balPath, targetPath string, command Command, debug DebugOptions, extraArgs ...string) exec.Cmd {
+	cmd := exec.Command(balPath, balArgs(string(command), targetPath, extraArgs)...)
+	if debug.Compiler {
+		cmd.Env = debug.compilerDebugEnv()
 	}
 	return *cmd
 }
 
-func createTestCommand(balPath, targetPath string, remoteDebug bool, extraArgs ...string) exec.Cmd {
-	return createExecCommand(balPath, targetPath, "test", remoteDebug)
+// balArgs places the bal flags before the target (if any) and the program arguments after the `--` separator that follows it
+func balArgs(subcommand, targetPath string, extraArgs []string) []string {
+	balFlags, programArgs := SplitProgramArgs(extraArgs)
+	args := []string{subcommand}
+	args = append(args, balFlags...)
+	if targetPath != "" {
+		args = append(args, targetPath)
+	}
+	if len(programArgs) > 0 {
+		args = append(args, "--")
+		args = append(args, programArgs...)
+	}
+	return args
 }
 
-func createRunCommand(balPath, targetPath string, remoteDebug bool, extraArgs ...string) exec.Cmd {
-	return createExecCommand(balPath, targetPath, "run", remoteDebug)
+// bal flags that take their value as the next argument
+var balValueFlags = map[string]bool{
+	"-o":                      true,
+	"--output":                true,
+	"--target-dir":            true,
+	"--debug":                 true,
+	"--cloud":                 true,
+	"--graalvm-build-options": true,
 }
 
-func createExecCommand(balPath, targetPath, runCommand string, remoteDebug bool) exec.Cmd {
-	args := []string{runCommand}
-	if remoteDebug {
-		args = append(args, "--debug", "5005")
+// SplitProgramArgs splits pass-through arguments at the first `--` into flags for bal and arguments for the
+// Ballerina program
+func SplitProgramArgs(args []string) ([]string, []string) {
+	for i, arg := range args {
+		if arg == "--" {
+			return args[:i], args[i+1:]
+		}
 	}
-	args = append(args, targetPath)
-	return *exec.Command(balPath, args...)
+	return args, nil
+}
+
+// SplitRunArgs splits the pass-through arguments of a program run like SplitProgramArgs, except that without a `--`
+// the program arguments start at the first argument that is not a bal flag or the value of one
+func SplitRunArgs(args []string) ([]string, []string) {
+	for _, arg := range args {
+		if arg == "--" {
+			return SplitProgramArgs(args)
+		}
+	}
+	for i := 0; i < len(args); i++ {
+		if !strings.HasPrefix(args[i], "-") {
+			return args[:i], args[i:]
+		}
+		if balValueFlags[args[i]] {
+			i++
+		}
+	}
+	return args, nil
+}
+
+// SplitPassThroughArgs separates the positional arguments of a command from the ones given after `--`, which are
+// passed through to bal
+func SplitPassThroughArgs(cmd *cobra.Command, args []string) ([]string, []string) {
+	dash := cmd.ArgsLenAtDash()
+	if dash < 0 {
+		return args, nil
+	}
+	return args[:dash], args[dash:]
 }
 
 func ExecuteCommand(cmd *exec.Cmd) error {
@@ -81,6 +187,15 @@ func ExecuteCommand(cmd *exec.Cmd) error {
 	return cmd.Run()
 }
 
+// CaptureCommandOutput runs the command and returns its standard output while still streaming standard error
+func CaptureCommandOutput(cmd *exec.Cmd) (string, error) {
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	return stdout.String(), err
+}
+
 type BenchmarkResult struct {
 	Iterations int
 	AvgTime    time.Duration
@@ -138,21 +253,99 @@ func PrettyPrintBenchmarkResult(result BenchmarkResult) {
 	fmt.Printf("Maximum time: %v\n", result.MaxTime)
 }
 
-func CompileTarget(sourcePath, version, targetPath string) {
-	command, err := CreateCommand(sourcePath, version, targetPath, Build, false)
-	ConsumeError(err)
-	err = ExecuteCommand(&command)
-	ConsumeError(err)
+func CompileTarget(toolchain Toolchain, targetPath string, args ...string) error {
+	command, err := CreateCommand(toolchain, targetPath, Build, DebugOptions{}, args...)
+	if err != nil {
+		return err
+	}
+	return ExecuteCommand(&command)
 }
 
+// GetExpectedOutput returns the path of the jar bal build writes for the target without any flags
 func GetExpectedOutput(path string) string {
+	return ExpectedOutput(path, nil)
+}
+
+// ExpectedOutput returns the path of the jar bal build writes for the target. Projects write to
+// <project>/target/bin/<package>.jar, or the target directory given by --target-dir or the targetDir build option,
+// while single files write <file>.jar to the working directory. -o/--output overrides both.
+func ExpectedOutput(path string, balFlags []string) string {
+	var jarName, outputDir string
 	if isBallerinaProject(path) {
-		return getProjectExpectedOutput(path)
+		name, targetDir := getProjectOutput(path)
+		if flagTargetDir, ok := balFlagValue(balFlags, "--target-dir"); ok {
+			targetDir = flagTargetDir
+		} else if targetDir == "" {
+			targetDir = filepath.Join(path, "target")
+		} else if !filepath.IsAbs(targetDir) {
+			targetDir = filepath.Join(path, targetDir)
+		}
+		jarName = name + ".jar"
+		outputDir = filepath.Join(targetDir, "bin")
+	} else {
+		jarName = strings.TrimSuffix(filepath.Base(path), ".bal") + ".jar"
+		outputDir = "."
+	}
+	if output, ok := balFlagValue(balFlags, "-o", "--output"); ok {
+		if info, err := os.Stat(output); err == nil && info.IsDir() {
+			return filepath.Join(output, jarName)
+		}
+		if !strings.HasSuffix(output, ".jar") {
+			output += ".jar"
+		}
+		return output
+	}
+	return filepath.Join(outputDir, jarName)
+}
+
+// FindOutputJar returns the jar bal build wrote for the target, listing the jars it could have meant if it doesn't
+// exist
+func FindOutputJar(path string, balFlags []string) (string, error) {
+	expected := ExpectedOutput(path, balFlags)
+	if _, err := os.Stat(expected); err == nil {
+		return expected, nil
+	}
+	candidates := candidateJars(path, filepath.Dir(expected))
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("%s not found and there are no other jars", expected)
+	}
+	return "", fmt.Errorf("%s not found, candidates are:\n  %s", expected, strings.Join(candidates, "\n  "))
+}
+
+func candidateJars(path, expectedDir string) []string {
+	dirs := []string{".", expectedDir}
+	if isBallerinaProject(path) {
+		dirs = append(dirs, path, filepath.Join(path, "target", "bin"))
+	} else {
+		dirs = append(dirs, filepath.Dir(path))
+	}
+	var candidates []string
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*.jar"))
+		for _, match := range matches {
+			if !seen[filepath.Clean(match)] {
+				seen[filepath.Clean(match)] = true
+				candidates = append(candidates, match)
+			}
+		}
+	}
+	return candidates
+}
+
+// balFlagValue returns the value of the first of the given flags in the bal flags, as either `-o x` or `-o=x`
+func balFlagValue(balFlags []string, names ...string) (string, bool) {
+	for i, flag := range balFlags {
+		for _, name := range names {
+			if value, found := strings.CutPrefix(flag, name+"="); found {
+				return value, true
+			}
+			if flag == name && i+1 < len(balFlags) {
+				return balFlags[i+1], true
+			}
+		}
 	}
-	fileName := filepath.Base(path)
-	fileName = strings.TrimSuffix(fileName, ".bal")
-	fileName += ".jar"
-	return fileName
+	return "", false
 }
 
 func isBallerinaProject(path string) bool {
@@ -174,32 +367,18 @@ func isBallerinaProject(path string) bool {
 	return err == nil
 }
 
-func getProjectExpectedOutput(path string) string {
-	balTomlPath := filepath.Join(path, "Ballerina.toml")
-	tomlContent, err := os.ReadFile(balTomlPath)
+// getProjectOutput reads the package name and the targetDir build option from Ballerina.toml
+func getProjectOutput(path string) (string, string) {
+	manifest, err := ReadBallerinaToml(path)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading Ballerina.toml file: %v\n", err)
 		os.Exit(1)
 	}
-
-	var config struct {
-		Package struct {
-			Name string `toml:"name"`
-		} `toml:"package"`
-	}
-
-	if err := toml.Unmarshal(tomlContent, &config); err != nil {
-		fmt.Fprintf(os.Stderr, "Error unmarshaling Ballerina.toml file: %v\n", err)
-		os.Exit(1)
-	}
-
-	name := config.Package.Name
-	return name + ".jar"
+	return manifest.Package.Name, manifest.BuildOptions.TargetDir
 }
 
 func BalPath(srcPath, version string) string {
-	return filepath.Join(srcPath, "distribution", "zip", "jballerina-tools", "build", "extracted-distributions",
-		fmt.Sprintf("jballerina-tools-%s", version), "bin", "bal")
+	return filepath.Join(extractedDistributionsDir(srcPath), extractedDistributionPrefix+version, "bin", "bal")
 }
 
 func CurrentWorkingDir() string {
@@ -215,29 +394,156 @@ func ConsumeError(err error) {
 	}
 }
 
-func buildCompilerIfNeeded(sourcePath, version string) error {
-	if shouldRebuildToolChain(sourcePath, version) {
-		return BuildCompiler(sourcePath, "build -x check")
+func buildCompilerIfNeeded(toolchain Toolchain) error {
+	if toolchain.Kind != CheckoutToolchain {
+		if !toolchain.IsBuilt() {
+			return fmt.Errorf("toolchain %s doesn't have a bal executable at %s", toolchain.Name, toolchain.BalPath())
+		}
+		return nil
+	}
+	report, err := updateStaleness(toolchain.Path, toolchain.Version)
+	if err != nil || !report.IsStale() {
+		return err
+	}
+	return withBuildLock(toolchain.Path, func() error {
+		// Another invocation may have rebuilt the toolchain while this one was waiting for the lock
+		report, err := updateStaleness(toolchain.Path, toolchain.Version)
+		if err != nil || !report.IsStale() {
+			return err
+		}
+		return rebuildStaleToolchain(toolchain, report)
+	})
+}
+
+func rebuildStaleToolchain(toolchain Toolchain, report StalenessReport) error {
+	version := versionOverride(toolchain.Path, toolchain.Version)
+	if !report.CompilerMissing && !report.UntrackedChanges {
+		rebuilt, err := rebuildIncrementally(toolchain.Path, version, report.Changes)
+		if rebuilt || err != nil {
+			return err
+		}
+	}
+	return BuildCompiler(toolchain.Path, viper.GetString("build.autoTasks"), version)
+}
+
+// BuildCompiler runs the gradle wrapper of the checkout with the given flags. If version is not empty the
+// distribution is built as that version instead of the one in gradle.properties.
+func BuildCompiler(path, flags, version string) error {
+	sources := snapshotSources(path, version)
+	if err := runGradle(path, gradleArgs(flags, version)); err != nil {
+		return err
 	}
+	finishBuild(path, version, sources)
 	return nil
 }
 
-func BuildCompiler(path, flags string) error {
-	args := strings.Split(strings.Trim(flags, " "), " ")
-	cmd := exec.Command("./gradlew", args...)
-	cmd.Dir = path
-	return ExecuteCommand(cmd)
+// snapshotSources computes the manifest of the checkout before a build starts, so that files edited while it runs
+// are seen as changed by the next staleness check. It returns nil if the manifest can't be computed.
+func snapshotSources(path, version string) *SourceManifest {
+	previous, _ := ReadManifest(path, builtVersion(path, version))
+	manifest, err := ComputeManifest(path, previous)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: unable to record the source manifest: %v\n", err)
+		return nil
+	}
+	return &manifest
 }
 
-func shouldRebuildToolChain(sourcePath, version string) bool {
+// builtVersion is the version the distribution is built as, the one in gradle.properties if version is empty
+func builtVersion(path, version string) string {
+	if version == "" {
+		version, _ = gradlePropertiesVersion(path)
+	}
+	return version
+}
+
+// finishBuild records the state of the checkout the distribution was built from, using the sources snapshot taken
+// before the build. The build already succeeded, so failing to record it only warns.
+func finishBuild(path, version string, sources *SourceManifest) {
+	if version == "" {
+		// Built as the version in gradle.properties, which takes over from a version chosen with buildTools --version
+		if err := clearBuildVersion(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: unable to clear the recorded build version: %v\n", err)
+		}
+	}
+	version = builtVersion(path, version)
+	if version == "" {
+		return
+	}
+	if sources != nil {
+		if err := WriteManifest(path, version, *sources); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: unable to record the source manifest: %v\n", err)
+		}
+	}
+	if err := WriteBuildInfo(checkoutBuildInfoPath(path, version), CurrentBuildInfo(path, version)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: unable to record the build info: %v\n", err)
+	}
+}
+
+// StalenessReport explains why the distribution of a checkout needs to be rebuilt
+type StalenessReport struct {
+	CompilerMissing bool
+	// The distribution was built without a manifest and there are source files newer than it
+	UntrackedChanges bool
+	Changes          []FileChange
+}
+
+func (r StalenessReport) IsStale() bool {
+	return r.CompilerMissing || r.UntrackedChanges || len(r.Changes) > 0
+}
+
+// CheckStaleness compares the checkout against the manifest recorded when its distribution was built, without
+// writing anything
+func CheckStaleness(sourcePath, version string) (StalenessReport, error) {
+	return checkStaleness(sourcePath, version, false)
+}
+
+// updateStaleness is CheckStaleness for commands that may rebuild the toolchain. It also records the baseline
+// manifest of distributions built without one and the new modification times of touched files.
+func updateStaleness(sourcePath, version string) (StalenessReport, error) {
+	return checkStaleness(sourcePath, version, true)
+}
+
+func checkStaleness(sourcePath, version string, record bool) (StalenessReport, error) {
 	balPath := BalPath(sourcePath, version)
 	if !compilerExists(balPath) {
-		return true
+		return StalenessReport{CompilerMissing: true}, nil
 	}
-	if sourceIsNewerThanCompiler(sourcePath, balPath) {
-		return true
+	previous, err := ReadManifest(sourcePath, version)
+	if os.IsNotExist(err) {
+		if sourceIsNewerThanCompiler(sourcePath, balPath) {
+			return StalenessReport{UntrackedChanges: true}, nil
+		}
+		if !record {
+			return StalenessReport{}, nil
+		}
+		// Use the current state as the baseline for the distributions built before manifests were recorded
+		return StalenessReport{}, recordManifest(sourcePath, version)
 	}
-	return false
+	if err != nil {
+		return StalenessReport{}, err
+	}
+	current, err := ComputeManifest(sourcePath, previous)
+	if err != nil {
+		return StalenessReport{}, err
+	}
+	changes := ChangedFiles(previous, current)
+	if record && len(changes) == 0 && manifestChanged(previous, current) {
+		// Only the modification times changed (ex: git checkout), remember them to avoid hashing the files again
+		if err := WriteManifest(sourcePath, version, current); err != nil {
+			return StalenessReport{}, err
+		}
+	}
+	return StalenessReport{Changes: changes}, nil
+}
+
+func recordManifest(sourcePath, version string) error {
+	previous, _ := ReadManifest(sourcePath, version)
+	manifest, err := ComputeManifest(sourcePath, previous)
+	if err != nil {
+		return err
+	}
+	return WriteManifest(sourcePath, version, manifest)
 }
 
 func sourceIsNewerThanCompiler(sourcePath, balPath string) bool {
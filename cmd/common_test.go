@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -9,9 +10,9 @@ func TestCreateCommandTest(t *testing.T) {
 	version := "1.0.0"
 	command := Test
 	targetPath := "/path/to/target"
-	remoteDebug := true
+	debug := DebugOptions{Runtime: true, RuntimePort: 5005, Suspend: true}
 
-	cmd, err := CreateCommandInner(sourcePath, version, targetPath, command, remoteDebug)
+	cmd, err := CreateCommandInner(NewCheckoutToolchain(sourcePath, version), targetPath, command, debug)
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -29,9 +30,9 @@ func TestCreateCommandRun(t *testing.T) {
 	version := "1.0.0"
 	command := Run
 	targetPath := "/path/to/target"
-	remoteDebug := true
+	debug := DebugOptions{Runtime: true, RuntimePort: 5005, Suspend: true}
 
-	cmd, err := CreateCommandInner(sourcePath, version, targetPath, command, remoteDebug)
+	cmd, err := CreateCommandInner(NewCheckoutToolchain(sourcePath, version), targetPath, command, debug)
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -49,9 +50,9 @@ func TestCreateCommandBuild(t *testing.T) {
 	version := "1.0.0"
 	command := Build
 	targetPath := "/path/to/target"
-	remoteDebug := true
+	debug := DebugOptions{Compiler: true, CompilerPort: 5005, Suspend: true}
 
-	cmd, err := CreateCommandInner(sourcePath, version, targetPath, command, remoteDebug)
+	cmd, err := CreateCommandInner(NewCheckoutToolchain(sourcePath, version), targetPath, command, debug)
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -67,6 +68,65 @@ func TestCreateCommandBuild(t *testing.T) {
 	}
 }
 
+func TestCreateCommandDebugCompilerAndRuntime(t *testing.T) {
+	sourcePath := "./path/to/source5"
+	version := "1.0.0"
+	targetPath := "/path/to/target"
+	debug := DebugOptions{Compiler: true, Runtime: true, CompilerPort: 6006, RuntimePort: 6005, Suspend: false}
+
+	cmd, err := CreateCommandInner(NewCheckoutToolchain(sourcePath, version), targetPath, Run, debug)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	expectedArgs := []string{BalPath(sourcePath, version), "run", "--debug", "6005", targetPath}
+	if !stringSlicesEqual(cmd.Args, expectedArgs) {
+		t.Errorf("Expected args to be %v, but got %v", expectedArgs, cmd.Args)
+	}
+
+	expectedAgent := "-agentlib:jdwp=transport=dt_socket,server=y,suspend=n,address=6006"
+	found := false
+	for _, env := range cmd.Env {
+		if strings.HasPrefix(env, "JAVA_OPTS=") && strings.HasSuffix(env, expectedAgent) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected JAVA_OPTS to contain %s, but got %v", expectedAgent, cmd.Env)
+	}
+}
+
+func TestCreateToolCommands(t *testing.T) {
+	sourcePath := "./path/to/source6"
+	version := "1.0.0"
+	balPath := BalPath(sourcePath, version)
+
+	testCases := []struct {
+		command      Command
+		targetPath   string
+		extraArgs    []string
+		expectedArgs []string
+	}{
+		{Pack, "/path/to/target", nil, []string{balPath, "pack", "/path/to/target"}},
+		{Clean, "", nil, []string{balPath, "clean"}},
+		{Bindgen, "", []string{"-cp", "lib.jar", "java.util.List"}, []string{balPath, "bindgen", "-cp", "lib.jar", "java.util.List"}},
+	}
+
+	for _, tc := range testCases {
+		debug := DebugOptions{Compiler: true, CompilerPort: 5005, Suspend: true}
+		cmd, err := CreateCommandInner(NewCheckoutToolchain(sourcePath, version), tc.targetPath, tc.command, debug, tc.extraArgs...)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if !stringSlicesEqual(cmd.Args, tc.expectedArgs) {
+			t.Errorf("Expected args to be %v, but got %v", tc.expectedArgs, cmd.Args)
+		}
+		if !stringArrayContains(cmd.Env, "BAL_JAVA_DEBUG=5005") {
+			t.Errorf("Expected env to contain BAL_JAVA_DEBUG=5005, but got %v", cmd.Env)
+		}
+	}
+}
+
 func stringArrayContains(arr []string, s string) bool {
 	for _, e := range arr {
 		if e == s {
@@ -88,6 +148,23 @@ func stringSlicesEqual(a, b []string) bool {
 	return true
 }
 
+func TestCreateJarDebugCommand(t *testing.T) {
+	testCases := []struct {
+		suspend  bool
+		expected []string
+	}{
+		{false, []string{"java", "-agentlib:jdwp=transport=dt_socket,server=y,suspend=n,address=5005", "-jar", "app.jar", "a"}},
+		{true, []string{"java", "-agentlib:jdwp=transport=dt_socket,server=y,suspend=y,address=5005", "-jar", "app.jar", "a"}},
+	}
+
+	for _, tc := range testCases {
+		command := CreateJarDebugCommand("app.jar", DebugOptions{Runtime: true, RuntimePort: 5005, Suspend: tc.suspend}, "a")
+		if !stringSlicesEqual(command.Args, tc.expected) {
+			t.Errorf("Expected args to be %v but got %v", tc.expected, command.Args)
+		}
+	}
+}
+
 func TestCreateJarRunCommand(t *testing.T) {
 	jarPath := "/path/to/jar"
 
@@ -98,3 +175,108 @@ func TestCreateJarRunCommand(t *testing.T) {
 		t.Errorf("Expected args to be %v, but got %v", expectedArgs, cmd.Args)
 	}
 }
+
+func TestCreateCommandPassThroughArgs(t *testing.T) {
+	sourcePath := "./path/to/source4"
+	version := "1.0.0"
+	targetPath := "/path/to/target"
+	extraArgs := []string{"--offline", "--", "arg1", "arg2"}
+
+	testCases := []struct {
+		command      Command
+		expectedArgs []string
+	}{
+		{Run, []string{"run", "--offline", targetPath, "--", "arg1", "arg2"}},
+		{Test, []string{"test", "--offline", targetPath, "--", "arg1", "arg2"}},
+		{Build, []string{"build", "--offline", targetPath, "--", "arg1", "arg2"}},
+	}
+
+	for _, tc := range testCases {
+		cmd, err := CreateCommandInner(NewCheckoutToolchain(sourcePath, version), targetPath, tc.command, DebugOptions{}, extraArgs...)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		expectedArgs := append([]string{BalPath(sourcePath, version)}, tc.expectedArgs...)
+		if !stringSlicesEqual(cmd.Args, expectedArgs) {
+			t.Errorf("Expected args to be %v, but got %v", expectedArgs, cmd.Args)
+		}
+	}
+}
+
+func TestSplitProgramArgs(t *testing.T) {
+	testCases := []struct {
+		args                []string
+		expectedBalFlags    []string
+		expectedProgramArgs []string
+	}{
+		{[]string{"--offline"}, []string{"--offline"}, nil},
+		{[]string{"--offline", "--", "a"}, []string{"--offline"}, []string{"a"}},
+		{[]string{"--", "a", "--", "b"}, []string{}, []string{"a", "--", "b"}},
+	}
+
+	for _, tc := range testCases {
+		balFlags, programArgs := SplitProgramArgs(tc.args)
+		if !stringSlicesEqual(balFlags, tc.expectedBalFlags) || !stringSlicesEqual(programArgs, tc.expectedProgramArgs) {
+			t.Errorf("Expected SplitProgramArgs(%v) to be %v, %v but got %v, %v", tc.args, tc.expectedBalFlags,
+				tc.expectedProgramArgs, balFlags, programArgs)
+		}
+	}
+}
+
+func TestSplitRunArgs(t *testing.T) {
+	testCases := []struct {
+		args                []string
+		expectedBalFlags    []string
+		expectedProgramArgs []string
+	}{
+		{[]string{"--offline"}, []string{"--offline"}, nil},
+		{[]string{"--offline", "--", "a"}, []string{"--offline"}, []string{"a"}},
+		{[]string{"a", "b"}, []string{}, []string{"a", "b"}},
+		{[]string{"--offline", "--debug", "5005", "a", "-x"}, []string{"--offline", "--debug", "5005"}, []string{"a", "-x"}},
+	}
+
+	for _, tc := range testCases {
+		balFlags, programArgs := SplitRunArgs(tc.args)
+		if !stringSlicesEqual(balFlags, tc.expectedBalFlags) || !stringSlicesEqual(programArgs, tc.expectedProgramArgs) {
+			t.Errorf("Expected SplitRunArgs(%v) to be %v, %v but got %v, %v", tc.args, tc.expectedBalFlags,
+				tc.expectedProgramArgs, balFlags, programArgs)
+		}
+	}
+}
+
+func TestCreateCommandReturnsBuildErrors(t *testing.T) {
+	toolchain := Toolchain{Name: "missing", Kind: DistributionToolchain, Path: t.TempDir()}
+	if _, err := CreateCommand(toolchain, "../testData/BalFile/main.bal", Build, DebugOptions{}); err == nil {
+		t.Errorf("Expected an error for a toolchain without a bal executable")
+	}
+	if err := CompileTarget(toolchain, "../testData/BalFile/main.bal"); err == nil {
+		t.Errorf("Expected an error compiling with a toolchain without a bal executable")
+	}
+}
+
+func TestCreateCommandProgramArgsOrder(t *testing.T) {
+	sourcePath := "./path/to/source5"
+	version := "1.0.0"
+	targetPath := "main.bal"
+
+	testCases := []struct {
+		extraArgs    []string
+		expectedArgs []string
+	}{
+		{[]string{"a", "b"}, []string{"run", targetPath, "--", "a", "b"}},
+		{[]string{"--offline", "a", "b"}, []string{"run", "--offline", targetPath, "--", "a", "b"}},
+		{[]string{"--offline", "--", "a", "b"}, []string{"run", "--offline", targetPath, "--", "a", "b"}},
+		{[]string{"--offline"}, []string{"run", "--offline", targetPath}},
+	}
+
+	for _, tc := range testCases {
+		cmd, err := CreateCommandInner(NewCheckoutToolchain(sourcePath, version), targetPath, Run, DebugOptions{}, tc.extraArgs...)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		expectedArgs := append([]string{BalPath(sourcePath, version)}, tc.expectedArgs...)
+		if !stringSlicesEqual(cmd.Args, expectedArgs) {
+			t.Errorf("Expected args for %v to be %v, but got %v", tc.extraArgs, expectedArgs, cmd.Args)
+		}
+	}
+}
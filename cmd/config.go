@@ -0,0 +1,237 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	projectConfigName = ".jbalcomptools.toml"
+	envPrefix         = "JBAL"
+)
+
+// Flags whose name differs from the key they are bound to
+var flagKeys = map[string]string{
+	"debug-port":          "debugPort",
+	"compiler-debug-port": "compilerDebugPort",
+	"suspend":             "debugSuspend",
+	"build-flags":         "build.autoTasks",
+}
+
+// ConfigLayer is a config file merged into the configuration. Later layers override earlier ones.
+type ConfigLayer struct {
+	Name     string
+	Path     string
+	settings map[string]interface{}
+}
+
+var configLayers []ConfigLayer
+
+func userConfigPath() string {
+	return expandHome(filepath.Join("~", ".config", "jBalCompTools", "config.toml"))
+}
+
+// loadUserConfig reads the user config followed by a config.toml in the working directory. Values from the environment
+// prefixed with JBAL_ override both, ex: JBAL_SOURCEPATH or JBAL_BUILD_AUTOTASKS.
+func loadUserConfig() {
+	readEnv(viper.GetViper())
+	for _, layer := range []struct{ name, path string }{{"user", userConfigPath()}, {"local", "config.toml"}} {
+		if _, err := os.Stat(layer.path); err != nil {
+			continue
+		}
+		if err := mergeConfigLayer(viper.GetViper(), layer.name, layer.path); err != nil {
+			fmt.Fprintln(os.Stderr, "Can't read config:", err)
+		}
+	}
+	if len(configLayers) == 0 {
+		fmt.Fprintln(os.Stderr, "Can't read config: no config.toml found (run jBalCompTools doctor to check the setup)")
+	}
+}
+
+// loadProjectConfig merges the closest .jbalcomptools.toml above the target over the user config
+func loadProjectConfig(targetPath string) {
+	path, ok := findProjectConfig(targetPath)
+	if !ok {
+		return
+	}
+	if err := mergeConfigLayer(viper.GetViper(), "project", path); err != nil {
+		fmt.Fprintln(os.Stderr, "Can't read config:", err)
+	}
+}
+
+// readEnv makes values from the environment prefixed with JBAL_ override the config files
+func readEnv(config *viper.Viper) {
+	config.SetEnvPrefix(envPrefix)
+	config.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	config.AutomaticEnv()
+}
+
+// mergeConfigLayer merges the config file at path into config, the global configuration outside of tests
+func mergeConfigLayer(config *viper.Viper, name, path string) error {
+	layer := viper.New()
+	layer.SetConfigFile(path)
+	layer.SetConfigType("toml")
+	if err := layer.ReadInConfig(); err != nil {
+		return err
+	}
+	return mergeLayer(config, name, path, layer)
+}
+
+func mergeLayer(config *viper.Viper, name, path string, layer *viper.Viper) error {
+	settings := make(map[string]interface{})
+	for _, key := range layer.AllKeys() {
+		settings[key] = layer.Get(key)
+	}
+	configLayers = append(configLayers, ConfigLayer{Name: name, Path: path, settings: settings})
+	return config.MergeConfigMap(layer.AllSettings())
+}
+
+// applyProfile merges the [profiles.<name>] table selected with --profile or JBAL_PROFILE over the config files.
+// Environment variables and flags still take precedence over it.
+func applyProfile(config *viper.Viper) error {
+	name := config.GetString("profile")
+	if name == "" {
+		return nil
+	}
+	profile := config.Sub("profiles." + name)
+	if profile == nil {
+		return fmt.Errorf("unknown profile %s, expected one of %s", name, strings.Join(profileNames(config), ", "))
+	}
+	return mergeLayer(config, "profile", name, profile)
+}
+
+func profileNames(config *viper.Viper) []string {
+	var names []string
+	for name := range config.GetStringMap("profiles") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// findProjectConfig walks up from the target looking for a project config
+func findProjectConfig(targetPath string) (string, bool) {
+	dir, err := filepath.Abs(targetPath)
+	if err != nil {
+		return "", false
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	for {
+		path := filepath.Join(dir, projectConfigName)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// configTarget is the path the project config is searched from: the first argument if it's a path, otherwise the
+// working directory
+func configTarget(args []string) string {
+	if len(args) > 0 {
+		if _, err := os.Stat(args[0]); err == nil {
+			return args[0]
+		}
+	}
+	return "."
+}
+
+func envName(key string) string {
+	return envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// ConfigSource describes the layer the effective value of the key comes from
+func ConfigSource(key string, flags *pflag.FlagSet, layers []ConfigLayer) string {
+	if flag := changedFlagFor(key, flags); flag != "" {
+		return "flag --" + flag
+	}
+	if _, ok := os.LookupEnv(envName(key)); ok {
+		return "env " + envName(key)
+	}
+	for i := len(layers) - 1; i >= 0; i-- {
+		if _, ok := layers[i].settings[strings.ToLower(key)]; ok {
+			return layers[i].Name + " " + layers[i].Path
+		}
+	}
+	return "default"
+}
+
+func changedFlagFor(key string, flags *pflag.FlagSet) string {
+	var name string
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if !flag.Changed {
+			return
+		}
+		flagKey, ok := flagKeys[flag.Name]
+		if !ok {
+			flagKey = flag.Name
+		}
+		if strings.EqualFold(flagKey, key) {
+			name = flag.Name
+		}
+	})
+	return name
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show [path]",
+	Short: "Show the config files in use, or with --resolved the effective value of each key",
+	Long: `Show the config files in use, or with --resolved the effective value of each key.
+Configuration is layered, each layer overriding the previous ones:
+  user     ~/.config/jBalCompTools/config.toml
+  local    config.toml in the working directory
+  project  the closest .jbalcomptools.toml above the target path
+  profile  the [profiles.<name>] table selected with --profile or JBAL_PROFILE
+  env      JBAL_<KEY> environment variables, with dots in keys replaced by underscores
+  flag     command line flags`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !viper.GetBool("config_resolved") {
+			for _, layer := range configLayers {
+				fmt.Printf("%-8s %s\n", layer.Name, layer.Path)
+			}
+			return
+		}
+		keys := viper.AllKeys()
+		sort.Strings(keys)
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "KEY\tVALUE\tSOURCE")
+		for _, key := range keys {
+			if key == "config_resolved" {
+				continue
+			}
+			fmt.Fprintf(writer, "%s\t%v\t%s\n", key, viper.Get(key), ConfigSource(key, cmd.Flags(), configLayers))
+		}
+		writer.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+	configShowCmd.Flags().Bool("resolved", false, "Print the effective value of each key and the layer it comes from")
+	viper.BindPFlag("config_resolved", configShowCmd.Flags().Lookup("resolved"))
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// setTestConfig sets a key of the global configuration until the end of the test. Setting nil drops the override,
+// and the previous value is only set again if it was itself an override.
+func setTestConfig(t *testing.T, key string, value interface{}) {
+	previous := viper.Get(key)
+	viper.Set(key, value)
+	t.Cleanup(func() {
+		viper.Set(key, nil)
+		if !reflect.DeepEqual(viper.Get(key), previous) {
+			viper.Set(key, previous)
+		}
+	})
+}
+
+func TestFindProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "project")
+	writeTestFile(t, filepath.Join(project, projectConfigName), "")
+	writeTestFile(t, filepath.Join(project, "modules", "foo", "foo.bal"), "")
+
+	testCases := []struct {
+		target   string
+		expected string
+		found    bool
+	}{
+		{filepath.Join(project, "modules", "foo", "foo.bal"), filepath.Join(project, projectConfigName), true},
+		{filepath.Join(project, "modules"), filepath.Join(project, projectConfigName), true},
+		{project, filepath.Join(project, projectConfigName), true},
+		{root, "", false},
+	}
+
+	for _, tc := range testCases {
+		actual, found := findProjectConfig(tc.target)
+		if found != tc.found || actual != tc.expected {
+			t.Errorf("Expected config of %s to be %s (%v) but got %s (%v)", tc.target, tc.expected, tc.found, actual, found)
+		}
+	}
+}
+
+func TestConfigLayering(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "config.toml")
+	project := filepath.Join(dir, projectConfigName)
+	writeTestFile(t, user, "testLayerA = \"user\"\ntestLayerB = \"user\"\n[testLayerTable]\nc = \"user\"\n")
+	writeTestFile(t, project, "testLayerB = \"project\"\n[testLayerTable]\nd = \"project\"\n")
+
+	saved := configLayers
+	defer func() { configLayers = saved }()
+	configLayers = nil
+	config := viper.New()
+	readEnv(config)
+	if err := mergeConfigLayer(config, "user", user); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := mergeConfigLayer(config, "project", project); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Setenv("JBAL_TESTLAYERENV", "env")
+	config.SetDefault("testLayerEnv", "default")
+
+	testCases := []struct {
+		key            string
+		expectedValue  string
+		expectedSource string
+	}{
+		{"testLayerA", "user", "user " + user},
+		{"testLayerB", "project", "project " + project},
+		{"testLayerTable.c", "user", "user " + user},
+		{"testLayerTable.d", "project", "project " + project},
+		{"testLayerEnv", "env", "env JBAL_TESTLAYERENV"},
+	}
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	for _, tc := range testCases {
+		if actual := config.GetString(tc.key); actual != tc.expectedValue {
+			t.Errorf("Expected %s to be %s but got %s", tc.key, tc.expectedValue, actual)
+		}
+		if actual := ConfigSource(tc.key, flags, configLayers); actual != tc.expectedSource {
+			t.Errorf("Expected %s to come from %s but got %s", tc.key, tc.expectedSource, actual)
+		}
+	}
+}
+
+func TestConfigSourceOfFlag(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.Int("debug-port", defaultDebugPort, "")
+	if actual := ConfigSource("debugPort", flags, nil); actual != "default" {
+		t.Errorf("Expected an unchanged flag to leave the default but got %s", actual)
+	}
+	flags.Parse([]string{"--debug-port", "6006"})
+	if actual := ConfigSource("debugport", flags, nil); actual != "flag --debug-port" {
+		t.Errorf("Expected the value to come from the flag but got %s", actual)
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "config.toml")
+	writeTestFile(t, user, "testProfileA = \"user\"\ntestProfileB = \"user\"\n[profiles.testperf]\ntestProfileB = \"perf\"\n")
+
+	saved := configLayers
+	defer func() { configLayers = saved }()
+	configLayers = nil
+	config := viper.New()
+	if err := mergeConfigLayer(config, "user", user); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	config.Set("profile", "missing")
+	if err := applyProfile(config); err == nil {
+		t.Errorf("Expected an error for an unknown profile")
+	}
+	config.Set("profile", "testperf")
+	if err := applyProfile(config); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if actual := config.GetString("testProfileA"); actual != "user" {
+		t.Errorf("Expected keys missing from the profile to keep their value but got %s", actual)
+	}
+	if actual := config.GetString("testProfileB"); actual != "perf" {
+		t.Errorf("Expected the profile to override the config but got %s", actual)
+	}
+	if actual := ConfigSource("testProfileB", flags, configLayers); actual != "profile testperf" {
+		t.Errorf("Expected the value to come from the profile but got %s", actual)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/viper"
+)
+
+const defaultDebugPort = 5005
+
+// DebugOptions describes which JVMs of a bal invocation wait for a remote debugger and on which ports
+type DebugOptions struct {
+	Compiler     bool
+	Runtime      bool
+	CompilerPort int
+	RuntimePort  int
+	Suspend      bool
+}
+
+// NewDebugOptions creates debug options using the configured ports. When both the compiler and the runtime are
+// debugged without a dedicated compiler port, the compiler listens on the port after the runtime's.
+func NewDebugOptions(compiler, runtime bool) DebugOptions {
+	compilerPort, port := debugPorts(runtime)
+	options := DebugOptions{
+		Compiler:     compiler,
+		Runtime:      runtime,
+		CompilerPort: compilerPort,
+		RuntimePort:  port,
+		Suspend:      viper.GetBool("debugSuspend"),
+	}
+	if compiler {
+		fmt.Fprintf(os.Stderr, "Compiler debugger listening on port %d\n", options.CompilerPort)
+	}
+	return options
+}
+
+// runsJar reports whether the runtime has to be started from the built jar, since `bal run --debug` always
+// suspends it until a debugger attaches
+func (d DebugOptions) runsJar() bool {
+	return d.Runtime && !d.Suspend
+}
+
+// jdwpAgent is the java option that starts a debug agent listening on the port
+func jdwpAgent(port int, suspend bool) string {
+	suspendFlag := "n"
+	if suspend {
+		suspendFlag = "y"
+	}
+	return fmt.Sprintf("-agentlib:jdwp=transport=dt_socket,server=y,suspend=%s,address=%d", suspendFlag, port)
+}
+
+// debugPorts returns the ports of the compiler and the runtime, depending on whether the runtime is also debugged
+func debugPorts(runtime bool) (int, int) {
+	port := viper.GetInt("debugPort")
+	compilerPort := viper.GetInt("compilerDebugPort")
+	if compilerPort == 0 {
+		compilerPort = port
+		if runtime {
+			compilerPort = port + 1
+		}
+	}
+	return compilerPort, port
+}
+
+// compilerDebugEnv returns the environment that makes the bal script start the compiler JVM with a debug agent
+func (d DebugOptions) compilerDebugEnv() []string {
+	if d.Suspend {
+		return append(os.Environ(), "BAL_JAVA_DEBUG="+strconv.Itoa(d.CompilerPort))
+	}
+	return append(os.Environ(), "JAVA_OPTS="+os.Getenv("JAVA_OPTS")+" "+jdwpAgent(d.CompilerPort, false))
+}
+
+// runtimeDebugArgs returns the bal flags that make the runtime wait for a debugger
+func (d DebugOptions) runtimeDebugArgs() []string {
+	return []string{"--debug", strconv.Itoa(d.RuntimePort)}
+}
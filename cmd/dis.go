@@ -24,7 +24,6 @@ package cmd
 import (
 	"fmt"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -32,32 +31,31 @@ import (
 
 // disCmd represents the dis command
 var disCmd = &cobra.Command{
-	Use:   "dis <path>",
+	Use:   "dis <path> [-- bal-flags...]",
 	Short: "Compile and dissemble a given file or project",
 	Run: func(cmd *cobra.Command, args []string) {
+		args, balFlags := SplitPassThroughArgs(cmd, args)
 		if len(args) != 1 {
 			fmt.Println("Please provide the path to ballerina source/project to dissemble")
 			os.Exit(1)
 		}
-		compileAndDissemble(args[0])
+		compileAndDissemble(ResolveTarget(args[0]), balFlags)
 	},
 }
 
-func compileAndDissemble(path string) {
-	CompileTarget(viper.GetString("sourcePath"), viper.GetString("version"), path)
-	jarName := GetExpectedOutput(path)
-	if _, err := os.Stat(jarName); os.IsNotExist(err) {
-		fmt.Fprintf(os.Stderr, "Error: %s not found\n", jarName)
-		os.Exit(1)
-	}
+// compileAndDissemble builds the target with the given bal flags and extracts the jar they produce
+func compileAndDissemble(path string, balFlags []string) {
+	ConsumeError(CompileTarget(CurrentToolchain(), path, balFlags...))
+	jarPath, err := FindOutputJar(path, balFlags)
+	ConsumeError(err)
 	createDisDir()
-	moveJarToDisDir(jarName)
-	disassemble(jarName)
+	moveJarToDisDir(jarPath)
+	disassemble(filepath.Base(jarPath))
 }
 
-func moveJarToDisDir(jarName string) {
-	disPath := filepath.Join("dis", jarName)
-	if err := os.Rename(jarName, disPath); err != nil {
+func moveJarToDisDir(jarPath string) {
+	disPath := filepath.Join("dis", filepath.Base(jarPath))
+	if err := os.Rename(jarPath, disPath); err != nil {
 		fmt.Fprintf(os.Stderr, "Error moving jar file: %v\n", err)
 		os.Exit(1)
 	}
@@ -91,4 +89,5 @@ func disassemble(path string) {
 // TODO: move this to common
 func init() {
 	rootCmd.AddCommand(disCmd)
+	addBuildFlagsFlag(disCmd.Flags())
 }
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -27,7 +29,7 @@ func TestGetExpectedOuput(t *testing.T) {
 		expected string
 	}{
 		{"../testData/BalFile/main.bal", "main.jar"},
-		{"../testData/BalProject", "BalProject.jar"},
+		{"../testData/BalProject", "../testData/BalProject/target/bin/BalProject.jar"},
 	}
 
 	for _, tc := range testCases {
@@ -37,3 +39,44 @@ func TestGetExpectedOuput(t *testing.T) {
 		}
 	}
 }
+
+func TestExpectedOutputWithFlags(t *testing.T) {
+	project := t.TempDir()
+	writeTestFile(t, filepath.Join(project, "Ballerina.toml"), "[package]\nname = \"custom\"\n\n[build-options]\ntargetDir = \"out\"\n")
+	testCases := []struct {
+		path     string
+		balFlags []string
+		expected string
+	}{
+		{"../testData/BalFile/main.bal", []string{"-o", "hello"}, "hello.jar"},
+		{"../testData/BalFile/main.bal", []string{"--output=build/hello.jar"}, "build/hello.jar"},
+		{"../testData/BalFile/main.bal", []string{"--offline", "-o", project}, filepath.Join(project, "main.jar")},
+		{"../testData/BalProject", []string{"--target-dir", "/tmp/out"}, "/tmp/out/bin/BalProject.jar"},
+		{project, nil, filepath.Join(project, "out", "bin", "custom.jar")},
+	}
+
+	for _, tc := range testCases {
+		actual := ExpectedOutput(tc.path, tc.balFlags)
+		if actual != tc.expected {
+			t.Errorf("Expected ExpectedOutput(%s, %v) to be %s, but got %s", tc.path, tc.balFlags, tc.expected, actual)
+		}
+	}
+}
+
+func TestFindOutputJar(t *testing.T) {
+	project := t.TempDir()
+	writeTestFile(t, filepath.Join(project, "Ballerina.toml"), "[package]\nname = \"app\"\n")
+	writeTestFile(t, filepath.Join(project, "target", "bin", "old.jar"), "")
+
+	_, err := FindOutputJar(project, nil)
+	if err == nil || !strings.Contains(err.Error(), filepath.Join(project, "target", "bin", "old.jar")) {
+		t.Errorf("Expected the error to list the candidate jars but got %v", err)
+	}
+
+	expected := filepath.Join(project, "target", "bin", "app.jar")
+	writeTestFile(t, expected, "")
+	actual, err := FindOutputJar(project, nil)
+	if err != nil || actual != expected {
+		t.Errorf("Expected %s but got %s, %v", expected, actual, err)
+	}
+}
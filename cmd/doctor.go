@@ -0,0 +1,212 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Problem is a failed doctor check together with how to fix it
+type Problem struct {
+	Err error
+	Fix string
+}
+
+type doctorCheck struct {
+	name  string
+	check func(Toolchain) *Problem
+}
+
+var doctorChecks = []doctorCheck{
+	{"Config file", checkConfigFile},
+	{"Source checkout", checkSourceCheckout},
+	{"Java tools", checkJavaTools},
+	{"Java version", checkJavaVersion},
+	{"Distribution", checkDistribution},
+	{"Debug port", checkDebugPort},
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the configuration and the environment",
+	Run: func(cmd *cobra.Command, args []string) {
+		toolchain := SelectedToolchain()
+		failed := 0
+		for _, check := range doctorChecks {
+			problem := check.check(toolchain)
+			if problem == nil {
+				fmt.Printf("[ok]   %s\n", check.name)
+				continue
+			}
+			failed++
+			fmt.Printf("[fail] %s: %v\n", check.name, problem.Err)
+			fmt.Printf("       fix: %s\n", problem.Fix)
+		}
+		if failed > 0 {
+			fmt.Printf("%d of %d checks failed\n", failed, len(doctorChecks))
+			os.Exit(1)
+		}
+	},
+}
+
+func checkConfigFile(Toolchain) *Problem {
+	if len(configLayers) > 0 {
+		return nil
+	}
+	return &Problem{
+		Err: fmt.Errorf("no config.toml found"),
+		Fix: "create config.toml in the current directory or in $HOME/.config/jBalCompTools with sourcePath and optionally version",
+	}
+}
+
+func checkSourceCheckout(toolchain Toolchain) *Problem {
+	if toolchain.Kind != CheckoutToolchain {
+		return nil
+	}
+	if toolchain.Path == "" {
+		return &Problem{
+			Err: fmt.Errorf("sourcePath is not set"),
+			Fix: "set sourcePath in config.toml or pass --sourcePath",
+		}
+	}
+	if info, err := os.Stat(toolchain.Path); err != nil || !info.IsDir() {
+		return &Problem{
+			Err: fmt.Errorf("%s is not a directory", toolchain.Path),
+			Fix: "clone https://github.com/ballerina-platform/ballerina-lang and point sourcePath to it",
+		}
+	}
+	if _, err := os.Stat(filepath.Join(toolchain.Path, "gradlew")); err != nil {
+		return &Problem{
+			Err: fmt.Errorf("%s doesn't have a gradlew, it is not a ballerina-lang checkout", toolchain.Path),
+			Fix: "point sourcePath to the root of the ballerina-lang checkout",
+		}
+	}
+	return nil
+}
+
+func checkJavaTools(Toolchain) *Problem {
+	var missing []string
+	for _, tool := range []string{"java", "javac", "jar"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			missing = append(missing, tool)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return &Problem{
+		Err: fmt.Errorf("%s not found on the PATH", strings.Join(missing, ", ")),
+		Fix: "install a JDK (not just a JRE) and add its bin directory to the PATH",
+	}
+}
+
+func checkJavaVersion(toolchain Toolchain) *Problem {
+	java := javaExecutable(toolchain)
+	version, err := JavaVersion(java)
+	if err != nil {
+		return &Problem{Err: err, Fix: "install a JDK and set JAVA_HOME or add it to the PATH"}
+	}
+	required, ok := requiredJavaMajor(toolchain.Version)
+	if !ok {
+		return nil
+	}
+	if major, ok := javaMajorVersion(version); ok && major < required {
+		return &Problem{
+			Err: fmt.Errorf("%s is java %s but ballerina %s requires java %d or newer", java, version,
+				toolchain.Version, required),
+			Fix: fmt.Sprintf("set JAVA_HOME to a JDK of java %d or newer", required),
+		}
+	}
+	return nil
+}
+
+// requiredJavaMajor is the java version a ballerina version runs on. Swan Lake moved to java 17 with update 8.
+func requiredJavaMajor(version string) (int, bool) {
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 || parts[0] != "2201" {
+		return 0, false
+	}
+	update, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, false
+	}
+	if update >= 8 {
+		return 17, true
+	}
+	return 11, true
+}
+
+// javaMajorVersion parses the major version of both the 1.8.0 and the 17.0.7 version schemes
+func javaMajorVersion(version string) (int, bool) {
+	version = strings.TrimPrefix(version, "1.")
+	end := strings.IndexAny(version, "._-+")
+	if end >= 0 {
+		version = version[:end]
+	}
+	major, err := strconv.Atoi(version)
+	return major, err == nil
+}
+
+func checkDistribution(toolchain Toolchain) *Problem {
+	if toolchain.IsBuilt() {
+		return nil
+	}
+	fix := fmt.Sprintf("check the path of toolchain %s", toolchain.Name)
+	if toolchain.Kind == CheckoutToolchain {
+		fix = "run jBalCompTools buildTools, or set version to one of the built distributions"
+		if versions := extractedDistributionVersions(toolchain.Path); len(versions) > 0 {
+			fix += " (" + strings.Join(versions, ", ") + ")"
+		}
+	}
+	return &Problem{Err: fmt.Errorf("%s doesn't exist", toolchain.BalPath()), Fix: fix}
+}
+
+// checkDebugPort checks the ports used to debug the runtime and the compiler, the latter being the one used when
+// both are debugged together
+func checkDebugPort(toolchain Toolchain) *Problem {
+	if port := os.Getenv("BAL_JAVA_DEBUG"); port != "" {
+		return &Problem{
+			Err: fmt.Errorf("BAL_JAVA_DEBUG is set, so %s waits for a debugger on port %s", toolchain.BalPath(), port),
+			Fix: "unset BAL_JAVA_DEBUG and use -r to debug the compiler",
+		}
+	}
+	compilerPort, runtimePort := debugPorts(true)
+	if !portAvailable(runtimePort) {
+		return &Problem{
+			Err: fmt.Errorf("port %d is in use", runtimePort),
+			Fix: "stop the process using it or choose another port with --debug-port or debugPort in config.toml",
+		}
+	}
+	if !portAvailable(compilerPort) {
+		return &Problem{
+			Err: fmt.Errorf("port %d used to debug the compiler is in use", compilerPort),
+			Fix: "stop the process using it or choose another port with --compiler-debug-port or compilerDebugPort " +
+				"in config.toml",
+		}
+	}
+	return nil
+}
+
+func portAvailable(port int) bool {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return false
+	}
+	listener.Close()
+	return true
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+func TestJavaMajorVersion(t *testing.T) {
+	testCases := []struct {
+		version  string
+		expected int
+	}{
+		{"17.0.7", 17},
+		{"1.8.0_381", 8},
+		{"21", 21},
+		{"11.0.2+9", 11},
+	}
+
+	for _, tc := range testCases {
+		actual, ok := javaMajorVersion(tc.version)
+		if !ok || actual != tc.expected {
+			t.Errorf("Expected major version of %s to be %d but got %d", tc.version, tc.expected, actual)
+		}
+	}
+}
+
+func TestRequiredJavaMajor(t *testing.T) {
+	testCases := []struct {
+		version  string
+		expected int
+		ok       bool
+	}{
+		{"2201.8.2", 17, true},
+		{"2201.10.0-SNAPSHOT", 17, true},
+		{"2201.4.1", 11, true},
+		{"swan-lake-beta", 0, false},
+	}
+
+	for _, tc := range testCases {
+		actual, ok := requiredJavaMajor(tc.version)
+		if ok != tc.ok || actual != tc.expected {
+			t.Errorf("Expected %s to require java %d (%v) but got %d (%v)", tc.version, tc.expected, tc.ok, actual, ok)
+		}
+	}
+}
+
+func TestCheckSourceCheckout(t *testing.T) {
+	checkout := t.TempDir()
+	if problem := checkSourceCheckout(Toolchain{Kind: CheckoutToolchain, Path: checkout}); problem == nil {
+		t.Errorf("Expected a checkout without a gradlew to fail")
+	}
+	writeTestFile(t, filepath.Join(checkout, "gradlew"), "")
+	if problem := checkSourceCheckout(Toolchain{Kind: CheckoutToolchain, Path: checkout}); problem != nil {
+		t.Errorf("Expected a checkout with a gradlew to pass but got %v", problem.Err)
+	}
+	if problem := checkSourceCheckout(Toolchain{Kind: CheckoutToolchain}); problem == nil {
+		t.Errorf("Expected an empty sourcePath to fail")
+	}
+}
+
+func TestCheckDebugPort(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer listener.Close()
+	busy := listener.Addr().(*net.TCPAddr).Port
+	free := freePort(t)
+	t.Setenv("BAL_JAVA_DEBUG", "")
+
+	testCases := []struct {
+		debugPort         int
+		compilerDebugPort int
+		inUse             bool
+	}{
+		{busy, 0, true},
+		{free, busy, true},
+		{free, freePort(t), false},
+	}
+
+	for _, tc := range testCases {
+		setTestConfig(t, "debugPort", tc.debugPort)
+		setTestConfig(t, "compilerDebugPort", tc.compilerDebugPort)
+		if problem := checkDebugPort(Toolchain{}); (problem != nil) != tc.inUse {
+			t.Errorf("Expected ports %d, %d in use to be %v but got %v", tc.debugPort, tc.compilerDebugPort, tc.inUse, problem)
+		}
+	}
+
+	t.Setenv("BAL_JAVA_DEBUG", "5005")
+	setTestConfig(t, "compilerDebugPort", 0)
+	if problem := checkDebugPort(Toolchain{}); problem == nil {
+		t.Errorf("Expected BAL_JAVA_DEBUG to be reported")
+	}
+}
+
+func freePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
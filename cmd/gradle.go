@@ -0,0 +1,96 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultAutoBuildTasks   = "build -x check"
+	defaultManualBuildTasks = "buildTools -x check"
+)
+
+// gradleArgs creates the arguments for the gradle wrapper from the given tasks and the `[build]` configuration
+func gradleArgs(tasks, version string) []string {
+	return gradleArgsWith(viper.GetViper(), tasks, version)
+}
+
+// gradleArgsWith reads the `[build]` configuration from the given viper instance. Properties are a list of
+// "name=value" strings since viper lowercases the keys of tables while gradle property names are case-sensitive.
+func gradleArgsWith(config *viper.Viper, tasks, version string) []string {
+	args := strings.Fields(tasks)
+	if _, isTable := config.Get("build.properties").(map[string]interface{}); isTable {
+		fmt.Fprintln(os.Stderr, "Warning: build.properties must be a list of \"name=value\" strings, ignoring it")
+	}
+	properties := config.GetStringSlice("build.properties")
+	sort.Strings(properties)
+	for _, property := range properties {
+		args = append(args, "-P"+property)
+	}
+	if config.GetBool("build.offline") {
+		args = append(args, "--offline")
+	}
+	if jvmArgs := config.GetString("build.jvmArgs"); jvmArgs != "" {
+		args = append(args, "-Dorg.gradle.jvmargs="+jvmArgs)
+	}
+	if version != "" {
+		args = append(args, "-Pversion="+version)
+	}
+	return args
+}
+
+// Gradle flags that take their value as the next argument
+var gradleValueFlags = map[string]bool{
+	"-x":             true,
+	"--exclude-task": true,
+	"-p":             true,
+	"--project-dir":  true,
+	"--max-workers":  true,
+}
+
+// gradleFlags drops the tasks from the given gradle arguments, keeping the flags and their values. It is used to
+// run other tasks with the flags of the configured build, ex: "-x check" of "build -x check".
+func gradleFlags(tasks string) string {
+	var flags []string
+	args := strings.Fields(tasks)
+	for i := 0; i < len(args); i++ {
+		if !strings.HasPrefix(args[i], "-") {
+			continue
+		}
+		flags = append(flags, args[i])
+		if gradleValueFlags[args[i]] && i+1 < len(args) {
+			i++
+			flags = append(flags, args[i])
+		}
+	}
+	return strings.Join(flags, " ")
+}
+
+// addBuildFlagsFlag adds the --build-flags override to the flags of a command that may rebuild the toolchain
+func addBuildFlagsFlag(flags *pflag.FlagSet) {
+	flags.String("build-flags", "", "Gradle tasks and flags used if the toolchain needs to be rebuilt")
+}
+
+// applyBuildFlagsOverride makes the --build-flags of the executing command, if given, the automatic rebuild tasks
+func applyBuildFlagsOverride(cmd *cobra.Command) {
+	flag := cmd.Flags().Lookup("build-flags")
+	if flag != nil && flag.Changed {
+		viper.Set("build.autoTasks", flag.Value.String())
+	}
+}
+
+func init() {
+	viper.SetDefault("build.autoTasks", defaultAutoBuildTasks)
+	viper.SetDefault("build.manualTasks", defaultManualBuildTasks)
+}
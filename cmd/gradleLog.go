@@ -0,0 +1,157 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+var (
+	javacErrorPattern  = regexp.MustCompile(`^(.+\.java):(\d+): error: (.*)$`)
+	testFailurePattern = regexp.MustCompile(`^(\S+ > .+) FAILED$`)
+	failedTaskPattern  = regexp.MustCompile(`Execution failed for task '([^']+)'`)
+)
+
+// CompileError is an error reported by javac
+type CompileError struct {
+	File    string
+	Line    string
+	Message string
+}
+
+func (e CompileError) String() string {
+	return fmt.Sprintf("%s:%s: %s", e.File, e.Line, e.Message)
+}
+
+// GradleFailure is what went wrong in a failed gradle build
+type GradleFailure struct {
+	CompileErrors []CompileError
+	FailedTests   []string
+	FailedTasks   []string
+}
+
+// ParseGradleOutput extracts the javac errors, failed tests and failed tasks from the output of a gradle build
+func ParseGradleOutput(output string) GradleFailure {
+	var failure GradleFailure
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if seen[line] {
+			// Gradle repeats the errors of a task in the final summary
+			continue
+		}
+		if match := javacErrorPattern.FindStringSubmatch(line); match != nil {
+			failure.CompileErrors = append(failure.CompileErrors, CompileError{File: match[1], Line: match[2], Message: match[3]})
+		} else if match := testFailurePattern.FindStringSubmatch(line); match != nil {
+			failure.FailedTests = append(failure.FailedTests, match[1])
+		} else if match := failedTaskPattern.FindStringSubmatch(line); match != nil {
+			failure.FailedTasks = append(failure.FailedTasks, match[1])
+		} else {
+			continue
+		}
+		seen[line] = true
+	}
+	return failure
+}
+
+func printGradleFailure(failure GradleFailure, logPath string) {
+	if len(failure.FailedTasks) > 0 {
+		fmt.Fprintln(os.Stderr, "Failed tasks:")
+		for _, task := range failure.FailedTasks {
+			fmt.Fprintln(os.Stderr, "  "+task)
+		}
+	}
+	if len(failure.CompileErrors) > 0 {
+		fmt.Fprintln(os.Stderr, "Compilation errors:")
+		for _, compileError := range failure.CompileErrors {
+			fmt.Fprintln(os.Stderr, "  "+compileError.String())
+		}
+	}
+	if len(failure.FailedTests) > 0 {
+		fmt.Fprintln(os.Stderr, "Failed tests:")
+		for _, test := range failure.FailedTests {
+			fmt.Fprintln(os.Stderr, "  "+test)
+		}
+	}
+	fmt.Fprintln(os.Stderr, "Full gradle output is in", logPath)
+}
+
+func gradleLogsDir() string {
+	return filepath.Join(CacheDir(), "logs")
+}
+
+func gradleLogPath() string {
+	return filepath.Join(gradleLogsDir(), "gradle-"+time.Now().Format("20060102-150405.000")+".log")
+}
+
+// GradleLogs lists the saved gradle logs most recent first, as stored distributions so that toolchain gc can prune
+// them with the same options
+func GradleLogs() ([]StoredDistribution, error) {
+	entries, err := os.ReadDir(gradleLogsDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var logs []StoredDistribution
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		logs = append(logs, StoredDistribution{
+			Path:     filepath.Join(gradleLogsDir(), entry.Name()),
+			Size:     info.Size(),
+			LastUsed: info.ModTime(),
+		})
+	}
+	sort.Slice(logs, func(i, j int) bool { return logs[i].LastUsed.After(logs[j].LastUsed) })
+	return logs, nil
+}
+
+// runGradle runs the gradle wrapper of the checkout, saving its output to a log file. The output is only streamed
+// with --verbose, otherwise a summary of the errors is printed when the build fails.
+func runGradle(sourcePath string, args []string) error {
+	logPath := gradleLogPath()
+	if err := os.MkdirAll(filepath.Dir(logPath), os.ModePerm); err != nil {
+		return err
+	}
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command("./gradlew", args...)
+	cmd.Dir = sourcePath
+	if viper.GetBool("verbose") {
+		cmd.Stdout = io.MultiWriter(os.Stdout, logFile)
+		cmd.Stderr = io.MultiWriter(os.Stderr, logFile)
+	} else {
+		fmt.Printf("Running gradle %s (output in %s)\n", strings.Join(args, " "), logPath)
+		cmd.Stdout = logFile
+		cmd.Stderr = logFile
+	}
+	if err := cmd.Run(); err != nil {
+		output, readErr := os.ReadFile(logPath)
+		if readErr == nil {
+			printGradleFailure(ParseGradleOutput(string(output)), logPath)
+		}
+		return fmt.Errorf("gradle build failed: %v", err)
+	}
+	return nil
+}
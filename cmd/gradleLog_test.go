@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestParseGradleOutput(t *testing.T) {
+	output := `> Task :ballerina-lang:compileJava
+/src/compiler/ballerina-lang/src/main/java/io/ballerina/Foo.java:42: error: cannot find symbol
+        bar();
+        ^
+  symbol:   method bar()
+1 error
+
+> Task :ballerina-runtime:test
+io.ballerina.runtime.FooTest > testBar FAILED
+    java.lang.AssertionError at FooTest.java:12
+
+FAILURE: Build completed with 2 failures.
+
+1: Task failed with an exception.
+-----------
+* What went wrong:
+Execution failed for task ':ballerina-lang:compileJava'.
+> Compilation failed; see the compiler error output for details.
+
+2: Task failed with an exception.
+-----------
+* What went wrong:
+Execution failed for task ':ballerina-runtime:test'.
+> There were failing tests.
+`
+	failure := ParseGradleOutput(output)
+
+	expectedError := "/src/compiler/ballerina-lang/src/main/java/io/ballerina/Foo.java:42: cannot find symbol"
+	if len(failure.CompileErrors) != 1 || failure.CompileErrors[0].String() != expectedError {
+		t.Errorf("Expected compile errors [%s] but got %v", expectedError, failure.CompileErrors)
+	}
+	expectedTests := []string{"io.ballerina.runtime.FooTest > testBar"}
+	if !stringSlicesEqual(failure.FailedTests, expectedTests) {
+		t.Errorf("Expected failed tests %v but got %v", expectedTests, failure.FailedTests)
+	}
+	expectedTasks := []string{":ballerina-lang:compileJava", ":ballerina-runtime:test"}
+	if !stringSlicesEqual(failure.FailedTasks, expectedTasks) {
+		t.Errorf("Expected failed tasks %v but got %v", expectedTasks, failure.FailedTasks)
+	}
+}
+
+func TestParseGradleOutputOfSuccessfulBuild(t *testing.T) {
+	failure := ParseGradleOutput("> Task :ballerina-lang:jar\n\nBUILD SUCCESSFUL in 3s\n")
+	if len(failure.CompileErrors) != 0 || len(failure.FailedTests) != 0 || len(failure.FailedTasks) != 0 {
+		t.Errorf("Expected no failures but got %+v", failure)
+	}
+}
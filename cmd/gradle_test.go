@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestGradleArgs(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	writeTestFile(t, configPath, `[build]
+offline = true
+jvmArgs = "-Xmx4g"
+properties = ["skipBallerinaTests=true", "a=b"]
+`)
+	config := viper.New()
+	config.SetConfigFile(configPath)
+	if err := config.ReadInConfig(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	args := gradleArgsWith(config, "build -x check", "2201.9.0")
+	expectedArgs := []string{"build", "-x", "check", "-Pa=b", "-PskipBallerinaTests=true", "--offline",
+		"-Dorg.gradle.jvmargs=-Xmx4g", "-Pversion=2201.9.0"}
+	if !stringSlicesEqual(args, expectedArgs) {
+		t.Errorf("Expected args to be %v, but got %v", expectedArgs, args)
+	}
+}
+
+func TestGradleFlags(t *testing.T) {
+	testCases := []struct {
+		tasks    string
+		expected string
+	}{
+		{"build -x check", "-x check"},
+		{"build --exclude-task check --info -x test", "--exclude-task check --info -x test"},
+		{"build", ""},
+		{"clean build --offline", "--offline"},
+	}
+
+	for _, tc := range testCases {
+		if actual := gradleFlags(tc.tasks); actual != tc.expected {
+			t.Errorf("Expected gradleFlags(%q) to be %q but got %q", tc.tasks, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultSubprojectTask = "jar"
+	defaultExtractTask    = ":jballerina-tools:extractDistribution"
+)
+
+var (
+	settingsIncludePattern     = regexp.MustCompile(`^\s*include\b(.*)$`)
+	settingsProjectNamePattern = regexp.MustCompile(`['"](:[^'"]+)['"]`)
+	settingsProjectDirPattern  = regexp.MustCompile(
+		`project\(\s*['"](:[^'"]+)['"]\s*\)\.projectDir\s*=\s*(?:new\s+File\(\s*rootDir\s*,\s*|file\(\s*)['"]([^'"]+)['"]`)
+)
+
+// GradleSubprojects maps the directory of each subproject in settings.gradle, relative to the checkout root, to the
+// gradle path of the subproject
+func GradleSubprojects(sourcePath string) (map[string]string, error) {
+	content, err := os.ReadFile(filepath.Join(sourcePath, "settings.gradle"))
+	if err != nil {
+		return nil, err
+	}
+	projectDirs := make(map[string]string)
+	for _, line := range strings.Split(string(content), "\n") {
+		if match := settingsProjectDirPattern.FindStringSubmatch(line); match != nil {
+			projectDirs[match[1]] = strings.TrimSuffix(filepath.ToSlash(match[2]), "/")
+			continue
+		}
+		if match := settingsIncludePattern.FindStringSubmatch(line); match != nil {
+			for _, name := range settingsProjectNamePattern.FindAllStringSubmatch(match[1], -1) {
+				if _, ok := projectDirs[name[1]]; !ok {
+					projectDirs[name[1]] = strings.ReplaceAll(strings.TrimPrefix(name[1], ":"), ":", "/")
+				}
+			}
+		}
+	}
+	subprojects := make(map[string]string, len(projectDirs))
+	for name, dir := range projectDirs {
+		subprojects[dir] = name
+	}
+	return subprojects, nil
+}
+
+// SubprojectsOf maps the changed files to the subprojects containing them. It fails if a file doesn't belong to
+// any subproject (ex: the root build scripts), in which case the whole checkout needs to be rebuilt.
+func SubprojectsOf(changes []FileChange, subprojects map[string]string) ([]string, error) {
+	affected := make(map[string]bool)
+	for _, change := range changes {
+		name, ok := subprojectOf(change.Path, subprojects)
+		if !ok {
+			return nil, fmt.Errorf("%s doesn't belong to a subproject", change.Path)
+		}
+		affected[name] = true
+	}
+	names := make([]string, 0, len(affected))
+	for name := range affected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// subprojectOf finds the subproject with the longest directory containing the path
+func subprojectOf(path string, subprojects map[string]string) (string, bool) {
+	for dir := filepath.ToSlash(filepath.Dir(path)); dir != "." && dir != "/"; dir = filepath.ToSlash(filepath.Dir(dir)) {
+		if name, ok := subprojects[dir]; ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// rebuildIncrementally rebuilds only the subprojects with changed files followed by the distribution extraction.
+// It returns false if the changes can't be handled incrementally.
+func rebuildIncrementally(sourcePath, version string, changes []FileChange) (bool, error) {
+	if !viper.GetBool("build.incremental") || len(changes) == 0 {
+		return false, nil
+	}
+	subprojects, err := GradleSubprojects(sourcePath)
+	if err != nil {
+		return false, nil
+	}
+	affected, err := SubprojectsOf(changes, subprojects)
+	if err != nil {
+		fmt.Printf("Doing a full rebuild since %v\n", err)
+		return false, nil
+	}
+	sources := snapshotSources(sourcePath, version)
+	task := viper.GetString("build.subprojectTask")
+	for _, subproject := range affected {
+		if err := timedGradleTask(sourcePath, subproject+":"+task, version, "Rebuilt "+subproject); err != nil {
+			return true, err
+		}
+	}
+	extractTask := viper.GetString("build.extractTask")
+	if err := timedGradleTask(sourcePath, extractTask, version, "Extracted distribution"); err != nil {
+		return true, err
+	}
+	finishBuild(sourcePath, version, sources)
+	return true, nil
+}
+
+func timedGradleTask(sourcePath, task, version, description string) error {
+	start := time.Now()
+	flags := gradleFlags(viper.GetString("build.autoTasks"))
+	if err := runGradle(sourcePath, gradleArgs(task+" "+flags, version)); err != nil {
+		return err
+	}
+	fmt.Printf("%s in %v\n", description, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+func init() {
+	viper.SetDefault("build.incremental", true)
+	viper.SetDefault("build.subprojectTask", defaultSubprojectTask)
+	viper.SetDefault("build.extractTask", defaultExtractTask)
+}
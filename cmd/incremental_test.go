@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSubprojectsOf(t *testing.T) {
+	sourcePath := t.TempDir()
+	settings := `rootProject.name = 'ballerina-lang'
+include(':ballerina-lang')
+include(':ballerina-runtime', ':ballerina-lang:int')
+project(':ballerina-lang').projectDir = file('compiler/ballerina-lang')
+project(':ballerina-runtime').projectDir = new File(rootDir, 'bvm/ballerina-runtime')
+`
+	writeTestFile(t, filepath.Join(sourcePath, "settings.gradle"), settings)
+
+	subprojects, err := GradleSubprojects(sourcePath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectedSubprojects := map[string]string{
+		"compiler/ballerina-lang": ":ballerina-lang",
+		"bvm/ballerina-runtime":   ":ballerina-runtime",
+		"ballerina-lang/int":      ":ballerina-lang:int",
+	}
+	for dir, name := range expectedSubprojects {
+		if subprojects[dir] != name {
+			t.Errorf("Expected subproject at %s to be %s, but got %v", dir, name, subprojects)
+		}
+	}
+
+	changes := []FileChange{
+		{"compiler/ballerina-lang/src/main/java/Foo.java", ModifiedFile},
+		{"bvm/ballerina-runtime/src/main/java/Bar.java", AddedFile},
+		{"compiler/ballerina-lang/src/main/java/Baz.java", ModifiedFile},
+	}
+	affected, err := SubprojectsOf(changes, subprojects)
+	expectedAffected := []string{":ballerina-lang", ":ballerina-runtime"}
+	if err != nil || !stringSlicesEqual(affected, expectedAffected) {
+		t.Errorf("Expected affected subprojects to be %v, but got %v, %v", expectedAffected, affected, err)
+	}
+
+	if _, err := SubprojectsOf([]FileChange{{"build.gradle", ModifiedFile}}, subprojects); err == nil {
+		t.Errorf("Expected an error for a change outside of the subprojects")
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Directories that never contain inputs of the distribution
+var manifestIgnoredDirs = map[string]bool{
+	".git":         true,
+	".gradle":      true,
+	".idea":        true,
+	"node_modules": true,
+}
+
+// Output directories of gradle projects, which are only ignored next to the build script of the project
+var gradleOutputDirs = map[string]bool{
+	"build":  true,
+	"out":    true,
+	"target": true,
+}
+
+type ChangeKind string
+
+const (
+	AddedFile    ChangeKind = "added"
+	RemovedFile  ChangeKind = "removed"
+	ModifiedFile ChangeKind = "modified"
+)
+
+type FileChange struct {
+	Path string     `json:"path"`
+	Kind ChangeKind `json:"kind"`
+}
+
+type manifestEntry struct {
+	Hash    string `json:"hash"`
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"modTime"`
+}
+
+// SourceManifest records the content hashes of the files of a checkout the distribution was built from
+type SourceManifest struct {
+	Files map[string]manifestEntry `json:"files"`
+}
+
+func manifestPath(sourcePath, version string) string {
+	return filepath.Join(extractedDistributionsDir(sourcePath), extractedDistributionPrefix+version+".manifest.json")
+}
+
+func ReadManifest(sourcePath, version string) (SourceManifest, error) {
+	var manifest SourceManifest
+	content, err := os.ReadFile(manifestPath(sourcePath, version))
+	if err != nil {
+		return manifest, err
+	}
+	err = json.Unmarshal(content, &manifest)
+	return manifest, err
+}
+
+func WriteManifest(sourcePath, version string, manifest SourceManifest) error {
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(manifestPath(sourcePath, version), content, 0644)
+}
+
+// ComputeManifest hashes the inputs of the distribution in the checkout. Files whose size and modification time
+// match the previous manifest reuse the recorded hash instead of being read again.
+func ComputeManifest(sourcePath string, previous SourceManifest) (SourceManifest, error) {
+	manifest := SourceManifest{Files: make(map[string]manifestEntry)}
+	err := filepath.Walk(sourcePath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path == sourcePath {
+				return nil
+			}
+			if manifestIgnoredDirs[info.Name()] || isGradleOutputDir(sourcePath, path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relPath, err := filepath.Rel(sourcePath, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if !isDistributionInput(relPath) {
+			return nil
+		}
+		entry := manifestEntry{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+		if old, ok := previous.Files[relPath]; ok && old.Size == entry.Size && old.ModTime == entry.ModTime {
+			entry.Hash = old.Hash
+		} else if entry.Hash, err = hashFile(path); err != nil {
+			return err
+		}
+		manifest.Files[relPath] = entry
+		return nil
+	})
+	return manifest, err
+}
+
+// isGradleOutputDir decides whether the directory is the output directory of a gradle project, as opposed to a
+// source package that happens to share its name
+func isGradleOutputDir(sourcePath, path string) bool {
+	if !gradleOutputDirs[filepath.Base(path)] {
+		return false
+	}
+	relPath, err := filepath.Rel(sourcePath, path)
+	if err != nil {
+		return false
+	}
+	relPath = filepath.ToSlash(relPath)
+	if strings.HasPrefix(relPath, "src/") || strings.Contains(relPath, "/src/") {
+		return false
+	}
+	project := filepath.Dir(path)
+	for _, script := range []string{"build.gradle", "build.gradle.kts"} {
+		if _, err := os.Stat(filepath.Join(project, script)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isDistributionInput decides whether a file, given relative to the checkout root, affects the built distribution
+func isDistributionInput(relPath string) bool {
+	name := filepath.Base(relPath)
+	switch {
+	case name == "gradle.properties", name == "libs.versions.toml":
+		return true
+	case strings.HasSuffix(name, ".gradle"), strings.HasSuffix(name, ".gradle.kts"):
+		return true
+	}
+	return strings.HasPrefix(relPath, "src/main/") || strings.Contains(relPath, "/src/main/")
+}
+
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// ChangedFiles lists the files whose content differs between the manifests, sorted by path
+func ChangedFiles(old, current SourceManifest) []FileChange {
+	var changes []FileChange
+	for path, entry := range current.Files {
+		oldEntry, ok := old.Files[path]
+		if !ok {
+			changes = append(changes, FileChange{Path: path, Kind: AddedFile})
+		} else if oldEntry.Hash != entry.Hash {
+			changes = append(changes, FileChange{Path: path, Kind: ModifiedFile})
+		}
+	}
+	for path := range old.Files {
+		if _, ok := current.Files[path]; !ok {
+			changes = append(changes, FileChange{Path: path, Kind: RemovedFile})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// manifestChanged reports whether any file metadata differs, so that refreshed modification times can be saved
+func manifestChanged(old, current SourceManifest) bool {
+	if len(old.Files) != len(current.Files) {
+		return true
+	}
+	for path, entry := range current.Files {
+		if old.Files[path] != entry {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestIsDistributionInput(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"compiler/ballerina-lang/src/main/java/Foo.java", true},
+		{"langlib/lang.int/src/main/ballerina/int.bal", true},
+		{"compiler/ballerina-lang/src/main/resources/compiler.properties", true},
+		{"compiler/ballerina-lang/build.gradle", true},
+		{"gradle.properties", true},
+		{"compiler/ballerina-lang/src/test/java/FooTest.java", false},
+		{"README.md", false},
+	}
+
+	for _, tc := range testCases {
+		if actual := isDistributionInput(tc.path); actual != tc.expected {
+			t.Errorf("Expected isDistributionInput(%s) to be %v, but got %v", tc.path, tc.expected, actual)
+		}
+	}
+}
+
+func TestUpdateStaleness(t *testing.T) {
+	sourcePath := t.TempDir()
+	version := "1.0.0"
+	javaFile := filepath.Join(sourcePath, "compiler", "src", "main", "java", "Foo.java")
+	writeTestFile(t, javaFile, "class Foo {}")
+	writeTestFile(t, filepath.Join(sourcePath, "compiler", "src", "test", "java", "FooTest.java"), "class FooTest {}")
+	writeTestFile(t, BalPath(sourcePath, version), "")
+
+	report, err := updateStaleness(sourcePath, version)
+	if err != nil || report.IsStale() {
+		t.Fatalf("Expected the toolchain to be up to date, but got %v, %v", report, err)
+	}
+
+	// Touching a file without changing its content doesn't make the toolchain stale
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(javaFile, later, later); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	report, err = updateStaleness(sourcePath, version)
+	if err != nil || report.IsStale() {
+		t.Fatalf("Expected the toolchain to be up to date after touching a file, but got %v, %v", report, err)
+	}
+
+	writeTestFile(t, javaFile, "class Foo { int x; }")
+	writeTestFile(t, filepath.Join(sourcePath, "langlib", "src", "main", "ballerina", "int.bal"), "")
+	report, err = updateStaleness(sourcePath, version)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []FileChange{
+		{"compiler/src/main/java/Foo.java", ModifiedFile},
+		{"langlib/src/main/ballerina/int.bal", AddedFile},
+	}
+	if len(report.Changes) != len(expected) || report.Changes[0] != expected[0] || report.Changes[1] != expected[1] {
+		t.Errorf("Expected changes to be %v, but got %v", expected, report.Changes)
+	}
+}
+
+func TestCheckStalenessDoesNotWriteManifest(t *testing.T) {
+	sourcePath := t.TempDir()
+	version := "1.0.0"
+	writeTestFile(t, filepath.Join(sourcePath, "compiler", "src", "main", "java", "Foo.java"), "class Foo {}")
+	writeTestFile(t, BalPath(sourcePath, version), "")
+
+	report, err := CheckStaleness(sourcePath, version)
+	if err != nil || report.IsStale() {
+		t.Fatalf("Expected the toolchain to be up to date, but got %v, %v", report, err)
+	}
+	if _, err := os.Stat(manifestPath(sourcePath, version)); !os.IsNotExist(err) {
+		t.Errorf("Expected CheckStaleness not to write a manifest, but got %v", err)
+	}
+}
+
+func TestFinishBuildRecordsSnapshot(t *testing.T) {
+	sourcePath := t.TempDir()
+	version := "1.0.0"
+	javaFile := filepath.Join(sourcePath, "compiler", "src", "main", "java", "Foo.java")
+	writeTestFile(t, javaFile, "class Foo {}")
+	writeTestFile(t, BalPath(sourcePath, version), "")
+
+	sources := snapshotSources(sourcePath, version)
+	// Edited while the build is running
+	writeTestFile(t, javaFile, "class Foo { int x; }")
+	finishBuild(sourcePath, version, sources)
+
+	report, err := CheckStaleness(sourcePath, version)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := FileChange{"compiler/src/main/java/Foo.java", ModifiedFile}
+	if len(report.Changes) != 1 || report.Changes[0] != expected {
+		t.Errorf("Expected changes to be [%v], but got %v", expected, report.Changes)
+	}
+}
+
+func TestComputeManifestIgnoresOnlyGradleOutputDirs(t *testing.T) {
+	sourcePath := t.TempDir()
+	writeTestFile(t, filepath.Join(sourcePath, "compiler", "build.gradle"), "")
+	writeTestFile(t, filepath.Join(sourcePath, "compiler", "build", "generated", "src", "main", "java", "Gen.java"), "")
+	writeTestFile(t, filepath.Join(sourcePath, "compiler", "src", "main", "java", "org", "foo", "build", "Bar.java"), "")
+	writeTestFile(t, filepath.Join(sourcePath, "compiler", "src", "main", "java", "org", "foo", "out", "build.gradle"), "")
+	writeTestFile(t, filepath.Join(sourcePath, "compiler", "src", "main", "java", "org", "foo", "out", "target", "Baz.java"), "")
+	writeTestFile(t, filepath.Join(sourcePath, "tools", "target", "src", "main", "java", "Tool.java"), "")
+	writeTestFile(t, filepath.Join(sourcePath, "tools", ".gradle", "src", "main", "java", "Cached.java"), "")
+
+	manifest, err := ComputeManifest(sourcePath, SourceManifest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"compiler/build.gradle", true},
+		{"compiler/build/generated/src/main/java/Gen.java", false},
+		{"compiler/src/main/java/org/foo/build/Bar.java", true},
+		{"compiler/src/main/java/org/foo/out/target/Baz.java", true},
+		{"tools/target/src/main/java/Tool.java", true},
+		{"tools/.gradle/src/main/java/Cached.java", false},
+	}
+	for _, tc := range testCases {
+		if _, actual := manifest.Files[tc.path]; actual != tc.expected {
+			t.Errorf("Expected %s to be in the manifest to be %v, but got %v", tc.path, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// BalModule is a module of a ballerina package with its source and test files relative to the module
+type BalModule struct {
+	Name    string
+	Path    string
+	Sources []string
+	Tests   []string
+}
+
+// BalProject is a ballerina package on disk
+type BalProject struct {
+	Path     string
+	Manifest BallerinaToml
+	Modules  []BalModule
+}
+
+// ReadProject reads the manifest of the project and finds its modules. The default module is the package root and
+// the other modules are the directories under modules/.
+func ReadProject(path string) (BalProject, error) {
+	manifest, err := ReadBallerinaToml(path)
+	if err != nil {
+		return BalProject{}, err
+	}
+	project := BalProject{Path: path, Manifest: manifest}
+	defaultModule, err := readModule(manifest.Package.Name, path)
+	if err != nil {
+		return project, err
+	}
+	project.Modules = append(project.Modules, defaultModule)
+	entries, err := os.ReadDir(filepath.Join(path, "modules"))
+	if err != nil && !os.IsNotExist(err) {
+		return project, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		module, err := readModule(manifest.Package.Name+"."+entry.Name(), filepath.Join(path, "modules", entry.Name()))
+		if err != nil {
+			return project, err
+		}
+		project.Modules = append(project.Modules, module)
+	}
+	return project, nil
+}
+
+func readModule(name, path string) (BalModule, error) {
+	module := BalModule{Name: name, Path: path}
+	var err error
+	if module.Sources, err = balFiles(path); err != nil {
+		return module, err
+	}
+	if module.Tests, err = balFiles(filepath.Join(path, "tests")); err != nil && !os.IsNotExist(err) {
+		return module, err
+	}
+	return module, nil
+}
+
+func balFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".bal") {
+			files = append(files, entry.Name())
+		}
+	}
+	return files, nil
+}
+
+var projectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "Inspect ballerina projects",
+}
+
+var projectInfoCmd = &cobra.Command{
+	Use:   "info [path]",
+	Short: "Show the modules, dependencies and build options of a project",
+	Run: func(cmd *cobra.Command, args []string) {
+		path := TargetFromArgs(args)
+		if !isBallerinaProject(path) {
+			fmt.Printf("%s is a single file, not a ballerina project\n", path)
+			os.Exit(1)
+		}
+		project, err := ReadProject(path)
+		ConsumeError(err)
+		printProjectInfo(project)
+		warnOnDistributionMismatch(CurrentToolchain(), path)
+	},
+}
+
+func printProjectInfo(project BalProject) {
+	pkg := project.Manifest.Package
+	fmt.Printf("Package:      %s/%s %s\n", pkg.Org, pkg.Name, pkg.Version)
+	fmt.Printf("Distribution: %s\n", valueOrDash(pkg.Distribution))
+	fmt.Println("Modules:")
+	for _, module := range project.Modules {
+		fmt.Printf("  %s\n", module.Name)
+		fmt.Printf("    sources: %s\n", valueOrDash(strings.Join(module.Sources, ", ")))
+		fmt.Printf("    tests:   %s\n", valueOrDash(strings.Join(module.Tests, ", ")))
+	}
+	if len(project.Manifest.Dependencies) > 0 {
+		fmt.Println("Dependencies:")
+		for _, dependency := range project.Manifest.Dependencies {
+			fmt.Printf("  %s\n", dependency)
+		}
+	}
+	platforms := make([]string, 0, len(project.Manifest.Platforms))
+	for platform := range project.Manifest.Platforms {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+	for _, platform := range platforms {
+		fmt.Printf("Platform libraries (%s):\n", platform)
+		for _, library := range project.Manifest.Platforms[platform].Dependencies {
+			fmt.Printf("  %s\n", library)
+		}
+	}
+	if settings := project.Manifest.BuildOptions.Settings(); len(settings) > 0 {
+		fmt.Println("Build options:")
+		for _, setting := range settings {
+			fmt.Printf("  %s\n", setting)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(projectCmd)
+	projectCmd.AddCommand(projectInfoCmd)
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestReadBallerinaToml(t *testing.T) {
+	project := t.TempDir()
+	writeTestFile(t, filepath.Join(project, "Ballerina.toml"), `[package]
+org = "heshan"
+name = "app"
+version = "0.1.0"
+distribution = "2201.9.0"
+
+[build-options]
+observabilityIncluded = true
+targetDir = "out"
+
+[[dependency]]
+org = "ballerina"
+name = "http"
+version = "2.10.0"
+
+[[platform.java17.dependency]]
+groupId = "io.foo"
+artifactId = "bar"
+version = "1.0.0"
+`)
+	manifest, err := ReadBallerinaToml(project)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if manifest.Package.Name != "app" || manifest.Package.Distribution != "2201.9.0" {
+		t.Errorf("Expected package app of distribution 2201.9.0 but got %+v", manifest.Package)
+	}
+	expectedSettings := []string{"observabilityIncluded = true", "targetDir = \"out\""}
+	if actual := manifest.BuildOptions.Settings(); !stringSlicesEqual(actual, expectedSettings) {
+		t.Errorf("Expected build options %v but got %v", expectedSettings, actual)
+	}
+	if len(manifest.Dependencies) != 1 || manifest.Dependencies[0].String() != "ballerina/http 2.10.0" {
+		t.Errorf("Expected dependency ballerina/http 2.10.0 but got %v", manifest.Dependencies)
+	}
+	libraries := manifest.Platforms["java17"].Dependencies
+	if len(libraries) != 1 || libraries[0].String() != "io.foo:bar:1.0.0" {
+		t.Errorf("Expected platform library io.foo:bar:1.0.0 but got %v", libraries)
+	}
+}
+
+func TestReadProject(t *testing.T) {
+	project := t.TempDir()
+	writeTestFile(t, filepath.Join(project, "Ballerina.toml"), "[package]\norg = \"heshan\"\nname = \"app\"\n")
+	writeTestFile(t, filepath.Join(project, "main.bal"), "")
+	writeTestFile(t, filepath.Join(project, "tests", "main_test.bal"), "")
+	writeTestFile(t, filepath.Join(project, "modules", "util", "util.bal"), "")
+	writeTestFile(t, filepath.Join(project, "modules", "util", "Module.md"), "")
+
+	actual, err := ReadProject(project)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(actual.Modules) != 2 {
+		t.Fatalf("Expected 2 modules but got %+v", actual.Modules)
+	}
+	testCases := []struct {
+		module  BalModule
+		name    string
+		sources []string
+		tests   []string
+	}{
+		{actual.Modules[0], "app", []string{"main.bal"}, []string{"main_test.bal"}},
+		{actual.Modules[1], "app.util", []string{"util.bal"}, nil},
+	}
+	for _, tc := range testCases {
+		if tc.module.Name != tc.name || !stringSlicesEqual(tc.module.Sources, tc.sources) || !stringSlicesEqual(tc.module.Tests, tc.tests) {
+			t.Errorf("Expected module %s with sources %v and tests %v but got %+v", tc.name, tc.sources, tc.tests, tc.module)
+		}
+	}
+}
+
+func TestDistributionMismatch(t *testing.T) {
+	testCases := []struct {
+		distribution string
+		version      string
+		expected     bool
+	}{
+		{"2201.8.2", "2201.8.2", false},
+		{"2201.9.0", "2201.9.0-SNAPSHOT", false},
+		{"2201.8.2", "2201.9.0", true},
+		{"", "2201.9.0", false},
+	}
+
+	for _, tc := range testCases {
+		if actual := distributionMismatch(tc.distribution, tc.version); actual != tc.expected {
+			t.Errorf("Expected distributionMismatch(%s, %s) to be %v, but got %v", tc.distribution, tc.version, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	reproMetadataFile = "repro.toml"
+	reproExpectedFile = "expected.out"
+	reproSourceFile   = "main.bal"
+)
+
+const reproMainTemplate = `import ballerina/io;
+
+public function main() {
+    io:println("Hello, World!");
+}
+`
+
+const reproExpectedTemplate = "Hello, World!\n"
+
+var invalidPackageNameChars = regexp.MustCompile(`[^A-Za-z0-9_.]`)
+
+// ReproMetadata describes where a reproducer came from and what it shows
+type ReproMetadata struct {
+	Issue     string    `toml:"issue"`
+	Project   bool      `toml:"project"`
+	Toolchain string    `toml:"toolchain"`
+	Version   string    `toml:"version"`
+	Commit    string    `toml:"commit"`
+	Observed  string    `toml:"observed"`
+	CreatedAt time.Time `toml:"createdAt"`
+}
+
+// NewRepro creates a reproducer in dir that prints the content of its expected output file, so that it passes
+// `repro check` until it is edited to reproduce the issue
+func NewRepro(dir string, metadata ReproMetadata) error {
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("%s already exists", dir)
+	}
+	if projectRoot, ok := enclosingProject(filepath.Join(dir, reproSourceFile)); ok && !metadata.Project {
+		return fmt.Errorf("%s is inside the project %s, so its main.bal would be built as part of the project; "+
+			"create it elsewhere or use --project", dir, projectRoot)
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	files := map[string]string{
+		reproSourceFile:   reproMainTemplate,
+		reproExpectedFile: reproExpectedTemplate,
+	}
+	if metadata.Project {
+		files["Ballerina.toml"] = reproBallerinaToml(filepath.Base(dir), metadata.Version)
+	}
+	var content bytes.Buffer
+	if err := toml.NewEncoder(&content).Encode(metadata); err != nil {
+		return err
+	}
+	files[reproMetadataFile] = content.String()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func reproBallerinaToml(name, distribution string) string {
+	manifest := fmt.Sprintf("[package]\norg = \"repro\"\nname = %q\nversion = \"0.1.0\"\n", reproPackageName(name))
+	// Ballerina.toml only accepts released distributions, ex: 2201.9.0 for 2201.9.0-SNAPSHOT
+	if distribution = strings.TrimSuffix(distribution, "-SNAPSHOT"); distribution != "" {
+		manifest += fmt.Sprintf("distribution = %q\n", distribution)
+	}
+	return manifest + "\n[build-options]\nobservabilityIncluded = false\n"
+}
+
+// reproPackageName turns the name of the reproducer into a valid package name, ex: issue-1234 into issue_1234
+func reproPackageName(name string) string {
+	packageName := invalidPackageNameChars.ReplaceAllString(name, "_")
+	if packageName == "" || (packageName[0] >= '0' && packageName[0] <= '9') {
+		packageName = "repro_" + packageName
+	}
+	return packageName
+}
+
+func ReadReproMetadata(dir string) (ReproMetadata, error) {
+	var metadata ReproMetadata
+	_, err := toml.DecodeFile(filepath.Join(dir, reproMetadataFile), &metadata)
+	return metadata, err
+}
+
+// reproTarget is the path to run for the reproducer: the project directory or its source file
+func reproTarget(dir string, metadata ReproMetadata) string {
+	if metadata.Project {
+		return dir
+	}
+	return filepath.Join(dir, reproSourceFile)
+}
+
+var reproCmd = &cobra.Command{
+	Use:   "repro",
+	Short: "Create and check minimal reproducers",
+}
+
+var reproNewCmd = &cobra.Command{
+	Use:   "new <name>",
+	Short: "Create a reproducer from a template",
+	Long: `Create a reproducer from a template.
+The reproducer directory contains main.bal (and Ballerina.toml for the distribution of the current toolchain with
+--project), expected.out with the expected output and repro.toml describing the issue. It can be run right away with
+run and checked against the expected output with repro check.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("Please provide the name of the reproducer")
+			os.Exit(1)
+		}
+		toolchain := CurrentToolchain()
+		metadata := ReproMetadata{
+			Issue:     viper.GetString("repro_issue"),
+			Project:   viper.GetBool("repro_project"),
+			Toolchain: toolchain.String(),
+			Version:   toolchain.Version,
+			Commit:    toolchain.Commit(),
+			Observed:  viper.GetString("repro_observed"),
+			CreatedAt: time.Now(),
+		}
+		ConsumeError(NewRepro(args[0], metadata))
+		fmt.Printf("Created reproducer %s\n", args[0])
+		fmt.Printf("Run it with:   jBalCompTools run %s\n", reproTarget(args[0], metadata))
+		fmt.Printf("Check it with: jBalCompTools repro check %s\n", args[0])
+	},
+}
+
+var reproCheckCmd = &cobra.Command{
+	Use:   "check <dir> [-- bal-flags... [-- program-args...]]",
+	Short: "Run a reproducer and compare its output with the expected output",
+	Run: func(cmd *cobra.Command, args []string) {
+		args, passThrough := SplitPassThroughArgs(cmd, args)
+		if len(args) != 1 {
+			fmt.Println("Please provide the reproducer directory")
+			os.Exit(1)
+		}
+		metadata, err := ReadReproMetadata(args[0])
+		ConsumeError(err)
+		matches, actual, err := OutputMatches(CurrentToolchain(), reproTarget(args[0], metadata),
+			filepath.Join(args[0], reproExpectedFile), passThrough)
+		ConsumeError(err)
+		if matches {
+			fmt.Println("PASS: output matches", reproExpectedFile)
+			return
+		}
+		fmt.Println("FAIL: output differs from", reproExpectedFile)
+		fmt.Println("Actual output:")
+		fmt.Print(actual)
+		os.Exit(1)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reproCmd)
+	reproCmd.AddCommand(reproNewCmd)
+	reproCmd.AddCommand(reproCheckCmd)
+	reproNewCmd.Flags().Bool("project", false, "Create a project instead of a single file")
+	reproNewCmd.Flags().String("issue", "", "Link to the issue being reproduced")
+	reproNewCmd.Flags().String("observed", "", "Behaviour observed in the issue")
+	viper.BindPFlag("repro_project", reproNewCmd.Flags().Lookup("project"))
+	viper.BindPFlag("repro_issue", reproNewCmd.Flags().Lookup("issue"))
+	viper.BindPFlag("repro_observed", reproNewCmd.Flags().Lookup("observed"))
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewRepro(t *testing.T) {
+	testCases := []struct {
+		project  bool
+		kind     TargetKind
+		manifest bool
+	}{
+		{false, SingleFileTarget, false},
+		{true, ProjectRootTarget, true},
+	}
+
+	for _, tc := range testCases {
+		dir := filepath.Join(t.TempDir(), "issue-1234")
+		metadata := ReproMetadata{Issue: "https://github.com/ballerina-platform/ballerina-lang/issues/1234", Project: tc.project, Version: "2201.9.0", Observed: "compiler crash"}
+		if tc.project {
+			metadata.Version = "2201.9.0-SNAPSHOT"
+		}
+		if err := NewRepro(dir, metadata); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		target, err := ClassifyTarget(reproTarget(dir, metadata))
+		if err != nil || target.Kind != tc.kind {
+			t.Errorf("Expected the reproducer to be a %s but got %+v, %v", tc.kind, target, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, reproExpectedFile)); err != nil {
+			t.Errorf("Expected %s to be created but got %v", reproExpectedFile, err)
+		}
+		actual, err := ReadReproMetadata(dir)
+		if err != nil || actual.Issue != metadata.Issue || actual.Observed != metadata.Observed || actual.Project != tc.project {
+			t.Errorf("Expected metadata %+v but got %+v, %v", metadata, actual, err)
+		}
+		if !tc.manifest {
+			continue
+		}
+		manifest, err := ReadBallerinaToml(dir)
+		if err != nil || manifest.Package.Name != "issue_1234" || manifest.Package.Distribution != "2201.9.0" {
+			t.Errorf("Expected package issue_1234 for distribution 2201.9.0 but got %+v, %v", manifest.Package, err)
+		}
+		if err := NewRepro(dir, metadata); err == nil {
+			t.Errorf("Expected an error when the reproducer already exists")
+		}
+	}
+}
+
+func TestNewReproInsideProject(t *testing.T) {
+	projectRoot := t.TempDir()
+	writeTestFile(t, filepath.Join(projectRoot, "Ballerina.toml"), "[package]\nname = \"app\"\n")
+	dir := filepath.Join(projectRoot, "repros", "issue-1234")
+
+	if err := NewRepro(dir, ReproMetadata{}); err == nil {
+		t.Errorf("Expected an error for a single file reproducer inside a project")
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected %s not to be created but got %v", dir, err)
+	}
+	if err := NewRepro(dir, ReproMetadata{Project: true}); err != nil {
+		t.Errorf("Expected a project reproducer inside a project to be created but got %v", err)
+	}
+}
+
+func TestReproPackageName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{"issue-1234", "issue_1234"},
+		{"1234", "repro_1234"},
+		{"closure_bug", "closure_bug"},
+	}
+
+	for _, tc := range testCases {
+		if actual := reproPackageName(tc.name); actual != tc.expected {
+			t.Errorf("Expected reproPackageName(%s) to be %s, but got %s", tc.name, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func revisionsDir() string {
+	return filepath.Join(CacheDir(), "revisions")
+}
+
+func revisionBuildInfoPath(commit string) string {
+	return filepath.Join(revisionsDir(), commit, "build.json")
+}
+
+// ResolveRevision resolves a commit, branch or tag of the checkout to the full commit hash
+func ResolveRevision(sourcePath, rev string) (string, error) {
+	out, err := exec.Command("git", "-C", sourcePath, "rev-parse", "--verify", rev+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve revision %s in %s: %v", rev, sourcePath, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// RevisionToolchain returns the cached toolchain for the revision of the checkout, building it if it's not cached
+func RevisionToolchain(sourcePath, rev string) (Toolchain, error) {
+	commit, err := ResolveRevision(sourcePath, rev)
+	if err != nil {
+		return Toolchain{}, err
+	}
+	if toolchain, ok := cachedRevisionToolchain(commit); ok {
+		return toolchain, nil
+	}
+	// Locked by the worktree so that builds of other revisions and of the checkout itself can run meanwhile
+	err = withBuildLock(revisionWorktree(commit), func() error {
+		// Another invocation may have cached the revision while this one was waiting for the lock
+		if _, ok := cachedRevisionToolchain(commit); ok {
+			return nil
+		}
+		return buildRevision(sourcePath, commit)
+	})
+	if err != nil {
+		return Toolchain{}, err
+	}
+	toolchain, ok := cachedRevisionToolchain(commit)
+	if !ok {
+		return Toolchain{}, fmt.Errorf("building %s didn't produce a distribution", commit)
+	}
+	return toolchain, nil
+}
+
+// LookupRevisionToolchain returns the cached toolchain for the revision of the checkout without building it. A
+// revision that isn't cached is returned as a toolchain that isn't built.
+func LookupRevisionToolchain(sourcePath, rev string) (Toolchain, error) {
+	commit, err := ResolveRevision(sourcePath, rev)
+	if err != nil {
+		return Toolchain{}, err
+	}
+	if toolchain, ok := cachedRevisionToolchain(commit); ok {
+		return toolchain, nil
+	}
+	return Toolchain{Name: "rev-" + shortCommit(commit), Kind: DistributionToolchain,
+		Path: filepath.Join(revisionsDir(), commit)}, nil
+}
+
+func cachedRevisionToolchain(commit string) (Toolchain, bool) {
+	info, err := ReadBuildInfo(revisionBuildInfoPath(commit))
+	if err != nil {
+		return Toolchain{}, false
+	}
+	toolchain := Toolchain{
+		Name:    "rev-" + shortCommit(commit),
+		Kind:    DistributionToolchain,
+		Path:    filepath.Join(revisionsDir(), commit, extractedDistributionPrefix+info.Version),
+		Version: info.Version,
+	}
+	return toolchain, toolchain.IsBuilt()
+}
+
+func revisionWorktree(commit string) string {
+	return filepath.Join(CacheDir(), "worktrees", commit)
+}
+
+// buildRevision builds the commit in a temporary worktree of the checkout and moves the distribution to the cache
+func buildRevision(sourcePath, commit string) error {
+	worktree := revisionWorktree(commit)
+	removeWorktree(sourcePath, worktree)
+	if err := os.MkdirAll(filepath.Dir(worktree), os.ModePerm); err != nil {
+		return err
+	}
+	fmt.Printf("Building %s in %s\n", shortCommit(commit), worktree)
+	add := exec.Command("git", "-C", sourcePath, "worktree", "add", "--detach", worktree, commit)
+	if err := ExecuteCommand(add); err != nil {
+		return err
+	}
+	defer removeWorktree(sourcePath, worktree)
+
+	if err := BuildCompiler(worktree, viper.GetString("build.autoTasks"), ""); err != nil {
+		return err
+	}
+	version, err := DetectCheckoutVersion(worktree)
+	if err != nil {
+		return err
+	}
+	info := CurrentBuildInfo(worktree, version)
+	target := filepath.Join(revisionsDir(), commit)
+	if err := os.MkdirAll(target, os.ModePerm); err != nil {
+		return err
+	}
+	distribution := extractedDistributionPrefix + version
+	if err := os.Rename(filepath.Join(extractedDistributionsDir(worktree), distribution), filepath.Join(target, distribution)); err != nil {
+		return err
+	}
+	if err := WriteBuildInfo(revisionBuildInfoPath(commit), info); err != nil {
+		return err
+	}
+	fmt.Printf("Cached toolchain for %s at %s\n", shortCommit(commit), target)
+	return nil
+}
+
+func removeWorktree(sourcePath, worktree string) {
+	if _, err := os.Stat(worktree); os.IsNotExist(err) {
+		return
+	}
+	exec.Command("git", "-C", sourcePath, "worktree", "remove", "--force", worktree).Run()
+	os.RemoveAll(worktree)
+	exec.Command("git", "-C", sourcePath, "worktree", "prune").Run()
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 10 {
+		return commit[:10]
+	}
+	return commit
+}
+
+var toolchainBuildCmd = &cobra.Command{
+	Use:   "build --rev <commit|branch>",
+	Short: "Build the toolchain of a ballerina-lang revision into the cache",
+	Long: `Build the toolchain of a ballerina-lang revision into the cache.
+The revision is built in a git worktree of the configured checkout and the distribution is cached by commit. Other
+commands can use it with --rev, which skips the build when it is already cached.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("rev") == "" {
+			fmt.Println("Please provide the revision to build using --rev")
+			os.Exit(1)
+		}
+		toolchain := CurrentToolchain()
+		fmt.Printf("Toolchain %s is available at %s\n", toolchain.Name, toolchain.Path)
+	},
+}
+
+func init() {
+	toolchainCmd.AddCommand(toolchainBuildCmd)
+}
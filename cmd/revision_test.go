@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func initTestRepo(t *testing.T) string {
+	repo := t.TempDir()
+	writeTestFile(t, filepath.Join(repo, "gradle.properties"), "version=1.0.0\n")
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Skipf("git is not usable: %v %s", err, out)
+		}
+	}
+	return repo
+}
+
+func TestCachedRevisionToolchain(t *testing.T) {
+	repo := initTestRepo(t)
+	setTestConfig(t, "cacheDir", t.TempDir())
+
+	commit, err := ResolveRevision(repo, "HEAD")
+	if err != nil || len(commit) != 40 {
+		t.Fatalf("Expected HEAD to resolve to a commit, but got %s, %v", commit, err)
+	}
+	if _, err := ResolveRevision(repo, "no-such-branch"); err == nil {
+		t.Errorf("Expected an error for an unknown revision")
+	}
+
+	if _, ok := cachedRevisionToolchain(commit); ok {
+		t.Fatalf("Expected the revision not to be cached")
+	}
+	uncached, err := LookupRevisionToolchain(repo, "HEAD")
+	if err != nil || uncached.IsBuilt() {
+		t.Errorf("Expected the uncached revision to be reported as not built, but got %v, %v", uncached, err)
+	}
+	if _, err := os.Stat(filepath.Join(CacheDir(), "worktrees")); !os.IsNotExist(err) {
+		t.Errorf("Expected looking up the revision not to build it, but got %v", err)
+	}
+	if err := WriteBuildInfo(revisionBuildInfoPath(commit), BuildInfo{Commit: commit, Version: "1.0.0"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	balPath := filepath.Join(revisionsDir(), commit, "jballerina-tools-1.0.0", "bin", "bal")
+	writeTestFile(t, balPath, "")
+
+	toolchain, err := RevisionToolchain(repo, "HEAD")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if toolchain.Kind != DistributionToolchain || toolchain.BalPath() != balPath || toolchain.Version != "1.0.0" {
+		t.Errorf("Expected the cached distribution toolchain at %s, but got %v", balPath, toolchain)
+	}
+}
+
+func TestResolveToolchainFromRevision(t *testing.T) {
+	repo := initTestRepo(t)
+	setTestConfig(t, "cacheDir", t.TempDir())
+	setTestConfig(t, "toolchain", "")
+	setTestConfig(t, "sourcePath", repo)
+
+	commit, err := ResolveRevision(repo, "HEAD")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := WriteBuildInfo(revisionBuildInfoPath(commit), BuildInfo{Commit: commit, Version: "1.0.0"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	balPath := filepath.Join(revisionsDir(), commit, "jballerina-tools-1.0.0", "bin", "bal")
+	writeTestFile(t, balPath, "")
+
+	toolchain, err := ResolveToolchain("HEAD")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if toolchain.Kind != DistributionToolchain || toolchain.BalPath() != balPath {
+		t.Errorf("Expected the cached distribution of HEAD at %s, but got %v", balPath, toolchain)
+	}
+
+	checkout, err := ResolveToolchain(repo + "@1.2.3")
+	if err != nil || checkout.Kind != CheckoutToolchain || checkout.Path != repo {
+		t.Errorf("Expected the checkout at %s, but got %v, %v", repo, checkout, err)
+	}
+}
@@ -6,7 +6,6 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -17,6 +16,11 @@ import (
 var rootCmd = &cobra.Command{
 	Use:   "jBalCompTools",
 	Short: "Collection of useful commands for jBallerina compiler debugging",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		loadProjectConfig(configTarget(args))
+		ConsumeError(applyProfile(viper.GetViper()))
+		applyBuildFlagsOverride(cmd)
+	},
 }
 
 func Execute() {
@@ -27,18 +31,32 @@ func Execute() {
 }
 
 func init() {
-	viper.SetConfigName("config")
-	viper.SetConfigType("toml")
-	viper.AddConfigPath(".")
-	viper.AddConfigPath("$HOME/.config/jBalCompTools")
-	viper.AddConfigPath(".")
-	if err := viper.ReadInConfig(); err != nil {
-		fmt.Println("Can't read config:", err)
-	}
+	loadUserConfig()
 
 	rootCmd.PersistentFlags().StringP("sourcePath", "s", viper.GetString("defaultSourcePath"), "Path to jBallerina source code")
 	viper.BindPFlag("sourcePath", rootCmd.PersistentFlags().Lookup("sourcePath"))
 
 	rootCmd.PersistentFlags().StringP("version", "v", viper.GetString("defaultVersion"), "Version of jBallerina")
 	viper.BindPFlag("version", rootCmd.PersistentFlags().Lookup("version"))
+
+	rootCmd.PersistentFlags().StringP("toolchain", "t", "", "Name of a toolchain configured under [toolchains.<name>] to use instead of sourcePath and version")
+	viper.BindPFlag("toolchain", rootCmd.PersistentFlags().Lookup("toolchain"))
+
+	rootCmd.PersistentFlags().String("rev", "", "Use the cached toolchain of a ballerina-lang commit or branch, building it if needed")
+	viper.BindPFlag("rev", rootCmd.PersistentFlags().Lookup("rev"))
+
+	rootCmd.PersistentFlags().Int("debug-port", defaultDebugPort, "Port the remote debugger attaches to")
+	viper.BindPFlag("debugPort", rootCmd.PersistentFlags().Lookup("debug-port"))
+
+	rootCmd.PersistentFlags().Int("compiler-debug-port", 0, "Port for debugging the compiler when the runtime is debugged at the same time (defaults to debug-port + 1)")
+	viper.BindPFlag("compilerDebugPort", rootCmd.PersistentFlags().Lookup("compiler-debug-port"))
+
+	rootCmd.PersistentFlags().Bool("suspend", true, "Suspend the JVM until a debugger attaches")
+	viper.BindPFlag("debugSuspend", rootCmd.PersistentFlags().Lookup("suspend"))
+
+	rootCmd.PersistentFlags().Bool("verbose", false, "Stream the full gradle output instead of a summary of the errors")
+	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
+
+	rootCmd.PersistentFlags().String("profile", "", "Name of a [profiles.<name>] table in the config whose values override the rest of the config")
+	viper.BindPFlag("profile", rootCmd.PersistentFlags().Lookup("profile"))
 }
@@ -14,35 +14,47 @@ import (
 )
 
 var runCmd = &cobra.Command{
-	Use:   "run [path]",
+	Use:   "run [path] [-- bal-flags... [-- program-args...]]",
 	Short: "Run project or file",
 	Run: func(cmd *cobra.Command, args []string) {
-		var targetPath string
-		if len(args) > 0 {
-			targetPath = args[0]
-		} else {
-			if viper.GetBool("file_run") {
-				fmt.Println("Please provide a file to run")
-				os.Exit(1)
-			}
-			targetPath = CurrentWorkingDir()
-		}
+		args, passThrough := SplitPassThroughArgs(cmd, args)
+		targetPath := TargetFromArgs(args)
 		if viper.GetBool("benchmark_run") {
-			benchmarkRun(targetPath)
-		} else {
-			command, err := CreateCommand(viper.GetString("sourcePath"), viper.GetString("version"), targetPath, Run,
-				viper.GetBool("remote_run"))
-			ConsumeError(err)
-			err = ExecuteCommand(&command)
-			ConsumeError(err)
+			benchmarkRun(targetPath, passThrough)
+			return
+		}
+		debug := NewDebugOptions(viper.GetBool("remote_compiler_run"), viper.GetBool("remote_run"))
+		if debug.runsJar() {
+			debugRunJar(targetPath, debug, passThrough)
+			return
 		}
+		command, err := CreateCommand(CurrentToolchain(), targetPath, Run, debug, passThrough...)
+		ConsumeError(err)
+		err = ExecuteCommand(&command)
+		ConsumeError(err)
 	},
 }
 
-func benchmarkRun(path string) {
-	CompileTarget(viper.GetString("sourcePath"), viper.GetString("version"), path)
-	jarName := GetExpectedOutput(path)
-	command := CreateJarRunCommand(jarName)
+// debugRunJar builds the target and runs its jar with a debug agent that doesn't suspend the runtime
+func debugRunJar(path string, debug DebugOptions, passThrough []string) {
+	balFlags, programArgs := SplitRunArgs(passThrough)
+	compilerDebug := DebugOptions{Compiler: debug.Compiler, CompilerPort: debug.CompilerPort, Suspend: debug.Suspend}
+	command, err := CreateCommand(CurrentToolchain(), path, Build, compilerDebug, balFlags...)
+	ConsumeError(err)
+	ConsumeError(ExecuteCommand(&command))
+	jarPath, err := FindOutputJar(path, balFlags)
+	ConsumeError(err)
+	fmt.Fprintf(os.Stderr, "Runtime debugger listening on port %d\n", debug.RuntimePort)
+	command = CreateJarDebugCommand(jarPath, debug, programArgs...)
+	ConsumeError(ExecuteCommand(&command))
+}
+
+func benchmarkRun(path string, passThrough []string) {
+	balFlags, programArgs := SplitRunArgs(passThrough)
+	ConsumeError(CompileTarget(CurrentToolchain(), path, balFlags...))
+	jarPath, err := FindOutputJar(path, balFlags)
+	ConsumeError(err)
+	command := CreateJarRunCommand(jarPath, programArgs...)
 	result, err := BenchmarkCommand(&command)
 	ConsumeError(err)
 	PrettyPrintBenchmarkResult(result)
@@ -50,10 +62,11 @@ func benchmarkRun(path string) {
 
 func init() {
 	rootCmd.AddCommand(runCmd)
-	runCmd.Flags().BoolP("file", "f", false, "Run the given file")
+	addBuildFlagsFlag(runCmd.Flags())
 	runCmd.Flags().BoolP("remote", "r", false, "Remote debug the runtime")
+	runCmd.Flags().BoolP("remote-compiler", "c", false, "Remote debug the compiler")
 	runCmd.Flags().BoolP("benchmark", "b", false, "Benchmark the runtime")
-	viper.BindPFlag("file_run", runCmd.Flags().Lookup("file"))
 	viper.BindPFlag("remote_run", runCmd.Flags().Lookup("remote"))
+	viper.BindPFlag("remote_compiler_run", runCmd.Flags().Lookup("remote-compiler"))
 	viper.BindPFlag("benchmark_run", runCmd.Flags().Lookup("benchmark"))
 }
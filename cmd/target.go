@@ -0,0 +1,163 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type TargetKind string
+
+const (
+	// A .bal file that is not part of a project
+	SingleFileTarget TargetKind = "file"
+	// The root directory of a project, containing Ballerina.toml
+	ProjectRootTarget TargetKind = "project"
+	// A directory under modules/ of a project
+	ModuleTarget TargetKind = "module"
+	// Any other file or directory inside a project
+	InsideProjectTarget TargetKind = "path inside project"
+)
+
+// Target is a path given to a command, classified by what bal should compile for it
+type Target struct {
+	Kind        TargetKind
+	Path        string
+	ProjectRoot string
+	// Name of the module for module targets, ex: app.util
+	Module string
+}
+
+// BuildPath is the path to pass to bal: the file for single files, otherwise the project root
+func (t Target) BuildPath() string {
+	if t.Kind == SingleFileTarget {
+		return t.Path
+	}
+	return t.ProjectRoot
+}
+
+// TestFlags scopes bal test to the module for module targets, unless the tests were already selected with --tests
+func (t Target) TestFlags(balFlags []string) []string {
+	if t.Kind != ModuleTarget {
+		return balFlags
+	}
+	if _, ok := balFlagValue(balFlags, "--tests"); ok {
+		return balFlags
+	}
+	return append([]string{"--tests", t.Module + ":*"}, balFlags...)
+}
+
+// ClassifyTarget decides whether the path is a single file, a project root, a module of a project or some other
+// path inside a project by walking up to the closest Ballerina.toml
+func ClassifyTarget(path string) (Target, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Target{}, fmt.Errorf("%s doesn't exist", path)
+	}
+	if !info.IsDir() && !strings.HasSuffix(path, ".bal") {
+		return Target{}, fmt.Errorf("%s is not a .bal file or a ballerina project", path)
+	}
+	if info.IsDir() && isBallerinaProject(path) {
+		return Target{Kind: ProjectRootTarget, Path: path, ProjectRoot: path}, nil
+	}
+	projectRoot, ok := enclosingProject(path)
+	if !ok {
+		if info.IsDir() {
+			return Target{}, fmt.Errorf("%s is not a ballerina project and is not inside one", path)
+		}
+		return Target{Kind: SingleFileTarget, Path: path}, nil
+	}
+	target := Target{Kind: InsideProjectTarget, Path: path, ProjectRoot: projectRoot}
+	if module, ok := moduleOf(projectRoot, path); ok && info.IsDir() {
+		target.Kind = ModuleTarget
+		target.Module = module
+	}
+	return target, nil
+}
+
+// enclosingProject walks up from the parent of the path to the closest directory with a Ballerina.toml
+func enclosingProject(path string) (string, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	dir := filepath.Dir(abs)
+	for {
+		if isBallerinaProject(dir) {
+			return relativeTo(path, abs, dir), true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// relativeTo keeps the project root relative when the path given by the user was relative
+func relativeTo(path, abs, dir string) string {
+	if filepath.IsAbs(path) {
+		return dir
+	}
+	rel, err := filepath.Rel(filepath.Dir(abs), dir)
+	if err != nil {
+		return dir
+	}
+	return filepath.Join(filepath.Dir(path), rel)
+}
+
+// moduleOf returns the module name if the path is the directory of a module of the project
+func moduleOf(projectRoot, path string) (string, bool) {
+	rel, err := filepath.Rel(projectRoot, path)
+	if err != nil {
+		return "", false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) != 2 || parts[0] != "modules" {
+		return "", false
+	}
+	manifest, err := ReadBallerinaToml(projectRoot)
+	if err != nil {
+		return "", false
+	}
+	return manifest.Package.Name + "." + parts[1], true
+}
+
+// ResolveTarget classifies the path given to a command and returns the path to pass to bal, exiting with an
+// explanation when the path can't be compiled
+func ResolveTarget(path string) string {
+	return resolveTarget(path).BuildPath()
+}
+
+func resolveTarget(path string) Target {
+	target, err := ClassifyTarget(path)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	switch target.Kind {
+	case ModuleTarget:
+		fmt.Fprintf(os.Stderr, "Using project %s of module %s\n", target.ProjectRoot, target.Module)
+	case InsideProjectTarget:
+		fmt.Fprintf(os.Stderr, "Using project %s since %s is inside it\n", target.ProjectRoot, target.Path)
+	}
+	return target
+}
+
+// TargetFromArgs resolves the first argument, or the working directory if there are no arguments
+func TargetFromArgs(args []string) string {
+	return targetOfArgs(args).BuildPath()
+}
+
+func targetOfArgs(args []string) Target {
+	if len(args) > 0 {
+		return resolveTarget(args[0])
+	}
+	return resolveTarget(CurrentWorkingDir())
+}
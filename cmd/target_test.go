@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestClassifyTarget(t *testing.T) {
+	testCases := []struct {
+		path         string
+		kind         TargetKind
+		buildPath    string
+		expectedName string
+	}{
+		{"../testData/BalFile/main.bal", SingleFileTarget, "../testData/BalFile/main.bal", ""},
+		{"../testData/BalProject", ProjectRootTarget, "../testData/BalProject", ""},
+		{"../testData/BalProject/main.bal", InsideProjectTarget, "../testData/BalProject", ""},
+		{"../testData/BalProject/modules/util", ModuleTarget, "../testData/BalProject", "BalProject.util"},
+		{"../testData/BalProject/modules/util/util.bal", InsideProjectTarget, "../testData/BalProject", ""},
+		{"../testData/BalProject/modules", InsideProjectTarget, "../testData/BalProject", ""},
+	}
+
+	for _, tc := range testCases {
+		actual, err := ClassifyTarget(tc.path)
+		if err != nil {
+			t.Errorf("Unexpected error classifying %s: %v", tc.path, err)
+			continue
+		}
+		if actual.Kind != tc.kind || actual.BuildPath() != tc.buildPath || actual.Module != tc.expectedName {
+			t.Errorf("Expected %s to be a %s built from %s (module %s), but got %+v", tc.path, tc.kind, tc.buildPath, tc.expectedName, actual)
+		}
+	}
+}
+
+func TestClassifyInvalidTarget(t *testing.T) {
+	testCases := []string{
+		"../testData/missing.bal",
+		"../testData/BalProject/Ballerina.toml",
+		"../testData/BirDump",
+	}
+
+	for _, path := range testCases {
+		if _, err := ClassifyTarget(path); err == nil {
+			t.Errorf("Expected classifying %s to fail", path)
+		}
+	}
+}
+
+func TestTargetTestFlags(t *testing.T) {
+	module := Target{Kind: ModuleTarget, Module: "app.util"}
+	testCases := []struct {
+		target   Target
+		balFlags []string
+		expected []string
+	}{
+		{module, []string{"--offline"}, []string{"--tests", "app.util:*", "--offline"}},
+		{module, []string{"--tests", "testFoo"}, []string{"--tests", "testFoo"}},
+		{module, []string{"--tests=testFoo"}, []string{"--tests=testFoo"}},
+		{Target{Kind: ProjectRootTarget}, []string{"--offline"}, []string{"--offline"}},
+		{Target{Kind: InsideProjectTarget}, nil, nil},
+	}
+
+	for _, tc := range testCases {
+		if actual := tc.target.TestFlags(tc.balFlags); !stringSlicesEqual(actual, tc.expected) {
+			t.Errorf("Expected test flags of %+v with %v to be %v but got %v", tc.target, tc.balFlags, tc.expected, actual)
+		}
+	}
+}
@@ -22,23 +22,26 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var testCmd = &cobra.Command{
-	Use:   "test [path]",
+	Use:   "test [path] [-- bal-flags...]",
 	Short: "Run test suite",
 	Run: func(cmd *cobra.Command, args []string) {
-		var targetPath string
-		if len(args) > 0 {
-			targetPath = args[0]
-		} else {
-			targetPath = CurrentWorkingDir()
+		args, passThrough := SplitPassThroughArgs(cmd, args)
+		target := targetOfArgs(args)
+		debug := NewDebugOptions(viper.GetBool("remote_compiler_tests"), viper.GetBool("remote_tests"))
+		if debug.runsJar() {
+			fmt.Println("bal test always suspends the tests until a debugger attaches, --suspend=false can't be used with --remote")
+			os.Exit(1)
 		}
 
-		command, err := CreateCommand(viper.GetString("sourcePath"), viper.GetString("version"), targetPath, Test,
-			viper.GetBool("remote_run"))
+		command, err := CreateCommand(CurrentToolchain(), target.BuildPath(), Test, debug, target.TestFlags(passThrough)...)
 		ConsumeError(err)
 		err = ExecuteCommand(&command)
 		ConsumeError(err)
@@ -47,7 +50,10 @@ var testCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(testCmd)
+	addBuildFlagsFlag(testCmd.Flags())
 
 	testCmd.Flags().BoolP("remote", "r", false, "Remote debug the runtime")
+	testCmd.Flags().BoolP("remote-compiler", "c", false, "Remote debug the compiler")
 	viper.BindPFlag("remote_tests", testCmd.Flags().Lookup("remote"))
+	viper.BindPFlag("remote_compiler_tests", testCmd.Flags().Lookup("remote-compiler"))
 }
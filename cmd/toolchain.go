@@ -0,0 +1,320 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type ToolchainKind string
+
+const (
+	// A ballerina-lang checkout, the distribution is built from source when needed
+	CheckoutToolchain ToolchainKind = "checkout"
+	// An extracted jballerina-tools distribution directory
+	DistributionToolchain ToolchainKind = "distribution"
+	// A ballerina installation, managed by `bal dist`
+	ReleaseToolchain ToolchainKind = "release"
+)
+
+// Name of the toolchain created from the top level sourcePath and version configuration
+const defaultToolchainName = "default"
+
+type Toolchain struct {
+	Name    string
+	Kind    ToolchainKind
+	Path    string
+	Version string
+}
+
+// toolchainConfig is a `[toolchains.<name>]` entry in config.toml
+type toolchainConfig struct {
+	Kind    string `mapstructure:"kind"`
+	Path    string `mapstructure:"path"`
+	Version string `mapstructure:"version"`
+}
+
+func NewCheckoutToolchain(sourcePath, version string) Toolchain {
+	toolchain := Toolchain{Name: defaultToolchainName, Kind: CheckoutToolchain, Path: sourcePath}
+	return toolchain.withVersion(version)
+}
+
+// withVersion sets the version of the toolchain, detecting it from the checkout if it isn't given
+func (t Toolchain) withVersion(version string) Toolchain {
+	t.Version = version
+	if version != "" || t.Kind != CheckoutToolchain || t.Path == "" {
+		return t
+	}
+	detected, err := DetectCheckoutVersion(t.Path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning:", err)
+		return t
+	}
+	t.Version = detected
+	return t
+}
+
+// CurrentToolchain returns the toolchain selected with --toolchain, or the one configured by sourcePath and version.
+// If --rev is given the cached toolchain of that revision of the checkout is used instead.
+func CurrentToolchain() Toolchain {
+	return currentToolchain(RevisionToolchain)
+}
+
+// CurrentToolchainWithoutBuilding is CurrentToolchain for read-only queries. The toolchain of a --rev that isn't
+// cached yet is returned as a toolchain that isn't built instead of being built.
+func CurrentToolchainWithoutBuilding() Toolchain {
+	return currentToolchain(LookupRevisionToolchain)
+}
+
+func currentToolchain(revisionToolchain func(sourcePath, rev string) (Toolchain, error)) Toolchain {
+	rev := viper.GetString("rev")
+	if rev == "" {
+		return SelectedToolchain()
+	}
+	// The revision decides the version, so the one of the checkout isn't detected
+	toolchain := selectedToolchain(false)
+	if toolchain.Kind != CheckoutToolchain {
+		ConsumeError(fmt.Errorf("--rev requires a checkout toolchain, but %s is a %s", toolchain.Name, toolchain.Kind))
+	}
+	toolchain, err := revisionToolchain(toolchain.Path, rev)
+	ConsumeError(err)
+	return toolchain
+}
+
+// SelectedToolchain returns the toolchain selected with --toolchain, or the one configured by sourcePath and version,
+// ignoring --rev
+func SelectedToolchain() Toolchain {
+	return selectedToolchain(true)
+}
+
+// selectedToolchain returns the selected toolchain, detecting its version from the checkout if it isn't configured
+// and detect is set
+func selectedToolchain(detect bool) Toolchain {
+	name := viper.GetString("toolchain")
+	var toolchain Toolchain
+	if name == "" {
+		toolchain = Toolchain{Name: defaultToolchainName, Kind: CheckoutToolchain, Path: viper.GetString("sourcePath"),
+			Version: viper.GetString("version")}
+	} else {
+		var err error
+		toolchain, err = findRegisteredToolchain(name)
+		ConsumeError(err)
+	}
+	if detect {
+		return toolchain.withVersion(toolchain.Version)
+	}
+	return toolchain
+}
+
+// ResolveToolchain resolves either the name of a registered toolchain, a checkout given as `<sourcePath>[@<version>]`
+// or a revision of the selected checkout, which is built if it isn't cached. Missing parts of a checkout fall back
+// to the configured source path and version.
+func ResolveToolchain(spec string) (Toolchain, error) {
+	if spec == "" {
+		return CurrentToolchain(), nil
+	}
+	registry, err := RegisteredToolchains()
+	if err != nil {
+		return Toolchain{}, err
+	}
+	for _, toolchain := range registry {
+		if toolchain.Name == spec {
+			return toolchain.withVersion(toolchain.Version), nil
+		}
+	}
+	if toolchain, ok := revisionOfSelectedCheckout(spec); ok {
+		return RevisionToolchain(toolchain.Path, spec)
+	}
+	sourcePath, version, _ := strings.Cut(spec, "@")
+	if sourcePath == "" {
+		sourcePath = viper.GetString("sourcePath")
+	}
+	if version == "" {
+		version = viper.GetString("version")
+	}
+	toolchain := NewCheckoutToolchain(sourcePath, version)
+	toolchain.Name = spec
+	return toolchain, nil
+}
+
+// revisionOfSelectedCheckout decides whether the spec is a revision of the selected checkout rather than a path
+func revisionOfSelectedCheckout(spec string) (Toolchain, bool) {
+	if strings.Contains(spec, "@") {
+		return Toolchain{}, false
+	}
+	if _, err := os.Stat(spec); err == nil {
+		return Toolchain{}, false
+	}
+	toolchain := selectedToolchain(false)
+	if toolchain.Kind != CheckoutToolchain || toolchain.Path == "" {
+		return Toolchain{}, false
+	}
+	_, err := ResolveRevision(toolchain.Path, spec)
+	return toolchain, err == nil
+}
+
+// LookupToolchain returns the registered toolchain with the given name, detecting its version from the checkout if
+// the config doesn't give it
+func LookupToolchain(name string) (Toolchain, error) {
+	toolchain, err := findRegisteredToolchain(name)
+	if err != nil {
+		return Toolchain{}, err
+	}
+	return toolchain.withVersion(toolchain.Version), nil
+}
+
+func findRegisteredToolchain(name string) (Toolchain, error) {
+	registry, err := RegisteredToolchains()
+	if err != nil {
+		return Toolchain{}, err
+	}
+	var names []string
+	for _, toolchain := range registry {
+		if toolchain.Name == name {
+			return toolchain, nil
+		}
+		names = append(names, toolchain.Name)
+	}
+	return Toolchain{}, fmt.Errorf("unknown toolchain %s, registered toolchains: %s", name, strings.Join(names, ", "))
+}
+
+// RegisteredToolchains returns the toolchains in the `[toolchains.<name>]` sections of the config sorted by name.
+// Versions missing from the config are left empty, use withVersion to detect them.
+func RegisteredToolchains() ([]Toolchain, error) {
+	var configs map[string]toolchainConfig
+	if err := viper.UnmarshalKey("toolchains", &configs); err != nil {
+		return nil, fmt.Errorf("invalid toolchains configuration: %v", err)
+	}
+	var toolchains []Toolchain
+	for name, config := range configs {
+		if config.Path == "" {
+			return nil, fmt.Errorf("toolchain %s doesn't have a path", name)
+		}
+		path := expandHome(config.Path)
+		kind := ToolchainKind(config.Kind)
+		switch kind {
+		case CheckoutToolchain, DistributionToolchain, ReleaseToolchain:
+		case "":
+			kind = detectToolchainKind(path)
+		default:
+			return nil, fmt.Errorf("toolchain %s has unknown kind %s", name, config.Kind)
+		}
+		toolchains = append(toolchains, Toolchain{Name: name, Kind: kind, Path: path, Version: config.Version})
+	}
+	sort.Slice(toolchains, func(i, j int) bool { return toolchains[i].Name < toolchains[j].Name })
+	return toolchains, nil
+}
+
+func detectToolchainKind(path string) ToolchainKind {
+	if _, err := os.Stat(filepath.Join(path, "gradlew")); err == nil {
+		return CheckoutToolchain
+	}
+	if _, err := os.Stat(filepath.Join(path, "bin", "bal")); err == nil {
+		if _, err := os.Stat(filepath.Join(path, "distributions")); err != nil {
+			return DistributionToolchain
+		}
+	}
+	return ReleaseToolchain
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+func (t Toolchain) BalPath() string {
+	switch t.Kind {
+	case DistributionToolchain:
+		return filepath.Join(t.Path, "bin", "bal")
+	case ReleaseToolchain:
+		if t.Version == "" {
+			return filepath.Join(t.Path, "bin", "bal")
+		}
+		return filepath.Join(t.Path, "distributions", "ballerina-"+t.Version, "bin", "bal")
+	default:
+		return BalPath(t.Path, t.Version)
+	}
+}
+
+// DistributionPath is the root of the distribution containing the bal script
+func (t Toolchain) DistributionPath() string {
+	return filepath.Dir(filepath.Dir(t.BalPath()))
+}
+
+func (t Toolchain) IsBuilt() bool {
+	return compilerExists(t.BalPath())
+}
+
+// Commit returns the commit the toolchain's checkout is at, or an empty string if it is not known
+func (t Toolchain) Commit() string {
+	if t.Kind != CheckoutToolchain {
+		return ""
+	}
+	out, err := exec.Command("git", "-C", t.Path, "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (t Toolchain) String() string {
+	if t.Kind == CheckoutToolchain && t.Name != defaultToolchainName {
+		return t.Name
+	}
+	return t.Path + "@" + t.Version
+}
+
+var toolchainCmd = &cobra.Command{
+	Use:   "toolchain",
+	Short: "Manage jBallerina toolchains",
+}
+
+var toolchainListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the configured toolchains",
+	Run: func(cmd *cobra.Command, args []string) {
+		toolchains, err := RegisteredToolchains()
+		ConsumeError(err)
+		if sourcePath := viper.GetString("sourcePath"); sourcePath != "" {
+			toolchains = append([]Toolchain{NewCheckoutToolchain(sourcePath, viper.GetString("version"))}, toolchains...)
+		}
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "NAME\tKIND\tPATH\tVERSION\tCOMMIT\tBUILT")
+		for _, toolchain := range toolchains {
+			toolchain = toolchain.withVersion(toolchain.Version)
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%v\n", toolchain.Name, toolchain.Kind, toolchain.Path,
+				valueOrDash(toolchain.Version), valueOrDash(toolchain.Commit()), toolchain.IsBuilt())
+		}
+		writer.Flush()
+	},
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func init() {
+	rootCmd.AddCommand(toolchainCmd)
+	toolchainCmd.AddCommand(toolchainListCmd)
+}
@@ -0,0 +1,286 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// StoredDistribution is an extracted distribution on disk, either built in a checkout or cached for a revision
+type StoredDistribution struct {
+	Path     string
+	Cached   bool
+	Version  string
+	Commit   string
+	Size     int64
+	LastUsed time.Time
+}
+
+type GcOptions struct {
+	OlderThan time.Duration
+	Keep      int
+	MaxSize   int64
+}
+
+// lastUsedPath is the file in the cache directory whose modification time is when the distribution was last used
+func lastUsedPath(distributionPath string) string {
+	return filepath.Join(CacheDir(), "usage", cacheKey(distributionPath))
+}
+
+// recordToolchainUse remembers when the distribution of the toolchain was last used, for garbage collection
+func recordToolchainUse(toolchain Toolchain) {
+	path := lastUsedPath(toolchain.DistributionPath())
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err == nil {
+			os.WriteFile(path, nil, 0644)
+		}
+	}
+}
+
+func lastUsed(distributionPath string) time.Time {
+	if info, err := os.Stat(lastUsedPath(distributionPath)); err == nil {
+		return info.ModTime()
+	}
+	if info, err := os.Stat(distributionPath); err == nil {
+		return info.ModTime()
+	}
+	return time.Time{}
+}
+
+// StoredDistributions lists the distributions extracted in the given checkouts and the ones in the revision cache
+func StoredDistributions(sourcePaths []string) ([]StoredDistribution, error) {
+	var distributions []StoredDistribution
+	for _, sourcePath := range sourcePaths {
+		for _, version := range extractedDistributionVersions(sourcePath) {
+			path := filepath.Join(extractedDistributionsDir(sourcePath), extractedDistributionPrefix+version)
+			info, _ := ReadBuildInfo(checkoutBuildInfoPath(sourcePath, version))
+			distributions = append(distributions, storedDistribution(path, false, version, info.Commit))
+		}
+	}
+	commits, err := os.ReadDir(revisionsDir())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, commit := range commits {
+		info, err := ReadBuildInfo(revisionBuildInfoPath(commit.Name()))
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(revisionsDir(), commit.Name(), extractedDistributionPrefix+info.Version)
+		distributions = append(distributions, storedDistribution(path, true, info.Version, commit.Name()))
+	}
+	sort.Slice(distributions, func(i, j int) bool { return distributions[i].LastUsed.After(distributions[j].LastUsed) })
+	return distributions, nil
+}
+
+func storedDistribution(path string, cached bool, version, commit string) StoredDistribution {
+	return StoredDistribution{
+		Path:     path,
+		Cached:   cached,
+		Version:  version,
+		Commit:   commit,
+		Size:     directorySize(path),
+		LastUsed: lastUsed(path),
+	}
+}
+
+func directorySize(path string) int64 {
+	var size int64
+	filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// SelectForRemoval picks the distributions to prune. Distributions must be sorted most recently used first, and the
+// protected path is never selected.
+func SelectForRemoval(distributions []StoredDistribution, protected string, options GcOptions, now time.Time) []StoredDistribution {
+	var selected []StoredDistribution
+	var totalSize int64
+	kept := 0
+	for _, distribution := range distributions {
+		if distribution.Path == protected {
+			totalSize += distribution.Size
+			continue
+		}
+		remove := options.OlderThan > 0 && now.Sub(distribution.LastUsed) > options.OlderThan
+		remove = remove || (options.Keep > 0 && kept >= options.Keep)
+		remove = remove || (options.MaxSize > 0 && totalSize+distribution.Size > options.MaxSize)
+		if remove {
+			selected = append(selected, distribution)
+			continue
+		}
+		kept++
+		totalSize += distribution.Size
+	}
+	return selected
+}
+
+// RemoveDistribution deletes the distribution together with the state recorded about it
+func RemoveDistribution(distribution StoredDistribution) error {
+	if err := os.Remove(lastUsedPath(distribution.Path)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if distribution.Cached {
+		return os.RemoveAll(filepath.Dir(distribution.Path))
+	}
+	for _, suffix := range []string{".manifest.json", ".build.json"} {
+		if err := os.Remove(distribution.Path + suffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.RemoveAll(distribution.Path)
+}
+
+// ParseSize parses sizes such as 512MB, 20G or 1.5GB
+func ParseSize(size string) (int64, error) {
+	units := []struct {
+		suffix     string
+		multiplier float64
+	}{
+		{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+		{"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+	}
+	normalized := strings.ToUpper(strings.TrimSpace(size))
+	multiplier := 1.0
+	for _, unit := range units {
+		if strings.HasSuffix(normalized, unit.suffix) {
+			normalized = strings.TrimSuffix(normalized, unit.suffix)
+			multiplier = unit.multiplier
+			break
+		}
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(normalized), 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %s", size)
+	}
+	return int64(value * multiplier), nil
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	value := float64(size)
+	suffixes := []string{"KB", "MB", "GB", "TB"}
+	i := -1
+	for value >= unit && i < len(suffixes)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f%s", value, suffixes[i])
+}
+
+// knownCheckouts returns the configured checkout and the registered checkout toolchains
+func knownCheckouts() []string {
+	var sourcePaths []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if path != "" && !seen[path] {
+			seen[path] = true
+			sourcePaths = append(sourcePaths, path)
+		}
+	}
+	add(viper.GetString("sourcePath"))
+	toolchains, err := RegisteredToolchains()
+	ConsumeError(err)
+	for _, toolchain := range toolchains {
+		if toolchain.Kind == CheckoutToolchain {
+			add(toolchain.Path)
+		}
+	}
+	return sourcePaths
+}
+
+var toolchainLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the distributions built in the checkouts and cached for revisions",
+	Run: func(cmd *cobra.Command, args []string) {
+		distributions, err := StoredDistributions(knownCheckouts())
+		ConsumeError(err)
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "LOCATION\tVERSION\tCOMMIT\tSIZE\tLAST USED\tPATH")
+		var total int64
+		for _, distribution := range distributions {
+			location := "checkout"
+			if distribution.Cached {
+				location = "cache"
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", location, distribution.Version,
+				valueOrDash(shortCommit(distribution.Commit)), formatSize(distribution.Size),
+				distribution.LastUsed.Format("2006-01-02 15:04"), distribution.Path)
+			total += distribution.Size
+		}
+		writer.Flush()
+		fmt.Printf("Total: %s\n", formatSize(total))
+	},
+}
+
+var toolchainGcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Delete old distributions and gradle logs, never the distribution of the current toolchain",
+	Run: func(cmd *cobra.Command, args []string) {
+		options := GcOptions{OlderThan: viper.GetDuration("gc_older_than"), Keep: viper.GetInt("gc_keep")}
+		if maxSize := viper.GetString("gc_max_size"); maxSize != "" {
+			var err error
+			options.MaxSize, err = ParseSize(maxSize)
+			ConsumeError(err)
+		}
+		if options.OlderThan <= 0 && options.Keep <= 0 && options.MaxSize <= 0 {
+			fmt.Println("Please provide at least one of --older-than, --keep or --max-size")
+			os.Exit(1)
+		}
+		distributions, err := StoredDistributions(knownCheckouts())
+		ConsumeError(err)
+		protected := SelectedToolchain().DistributionPath()
+		var freed int64
+		for _, distribution := range SelectForRemoval(distributions, protected, options, time.Now()) {
+			fmt.Printf("Removing %s (%s)\n", distribution.Path, formatSize(distribution.Size))
+			if !viper.GetBool("gc_dry_run") {
+				ConsumeError(RemoveDistribution(distribution))
+			}
+			freed += distribution.Size
+		}
+		logs, err := GradleLogs()
+		ConsumeError(err)
+		for _, log := range SelectForRemoval(logs, "", options, time.Now()) {
+			fmt.Printf("Removing %s (%s)\n", log.Path, formatSize(log.Size))
+			if !viper.GetBool("gc_dry_run") {
+				ConsumeError(os.Remove(log.Path))
+			}
+			freed += log.Size
+		}
+		fmt.Printf("Freed %s\n", formatSize(freed))
+	},
+}
+
+func init() {
+	toolchainCmd.AddCommand(toolchainLsCmd)
+	toolchainCmd.AddCommand(toolchainGcCmd)
+	toolchainGcCmd.Flags().Duration("older-than", 0, "Delete distributions not used within this duration, ex: 720h")
+	toolchainGcCmd.Flags().Int("keep", 0, "Keep only this many of the most recently used distributions")
+	toolchainGcCmd.Flags().String("max-size", "", "Delete the least recently used distributions until the total size is below this, ex: 20GB")
+	toolchainGcCmd.Flags().Bool("dry-run", false, "Only show what would be deleted")
+	viper.BindPFlag("gc_older_than", toolchainGcCmd.Flags().Lookup("older-than"))
+	viper.BindPFlag("gc_keep", toolchainGcCmd.Flags().Lookup("keep"))
+	viper.BindPFlag("gc_max_size", toolchainGcCmd.Flags().Lookup("max-size"))
+	viper.BindPFlag("gc_dry_run", toolchainGcCmd.Flags().Lookup("dry-run"))
+}
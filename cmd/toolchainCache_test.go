@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSelectForRemoval(t *testing.T) {
+	now := time.Now()
+	distributions := []StoredDistribution{
+		{Path: "a", Size: 10, LastUsed: now.Add(-time.Hour)},
+		{Path: "current", Size: 10, LastUsed: now.Add(-100 * time.Hour)},
+		{Path: "b", Size: 10, LastUsed: now.Add(-2 * time.Hour)},
+		{Path: "c", Size: 10, LastUsed: now.Add(-48 * time.Hour)},
+		{Path: "d", Size: 10, LastUsed: now.Add(-72 * time.Hour)},
+	}
+
+	testCases := []struct {
+		options  GcOptions
+		expected []string
+	}{
+		{GcOptions{OlderThan: 24 * time.Hour}, []string{"c", "d"}},
+		{GcOptions{Keep: 1}, []string{"b", "c", "d"}},
+		{GcOptions{MaxSize: 35}, []string{"c", "d"}},
+		{GcOptions{}, nil},
+	}
+
+	for _, tc := range testCases {
+		var actual []string
+		for _, distribution := range SelectForRemoval(distributions, "current", tc.options, now) {
+			actual = append(actual, distribution.Path)
+		}
+		if !stringSlicesEqual(actual, tc.expected) {
+			t.Errorf("Expected %+v to remove %v, but got %v", tc.options, tc.expected, actual)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	testCases := []struct {
+		size     string
+		expected int64
+	}{
+		{"20GB", 20 << 30},
+		{"512m", 512 << 20},
+		{"1.5G", 3 << 29},
+		{"100", 100},
+	}
+
+	for _, tc := range testCases {
+		actual, err := ParseSize(tc.size)
+		if err != nil || actual != tc.expected {
+			t.Errorf("Expected ParseSize(%s) to be %d, but got %d, %v", tc.size, tc.expected, actual, err)
+		}
+	}
+	if _, err := ParseSize("lots"); err == nil {
+		t.Errorf("Expected an error for an invalid size")
+	}
+}
+
+func TestRecordToolchainUse(t *testing.T) {
+	cacheDir := t.TempDir()
+	setTestConfig(t, "cacheDir", cacheDir)
+	toolchain := NewCheckoutToolchain(t.TempDir(), "1.0.0")
+	writeTestFile(t, toolchain.BalPath(), "")
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(toolchain.DistributionPath(), old, old); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	recordToolchainUse(toolchain)
+	if used := lastUsed(toolchain.DistributionPath()); time.Since(used) > time.Hour {
+		t.Errorf("Expected the distribution to be used just now but got %v", used)
+	}
+	if !strings.HasPrefix(lastUsedPath(toolchain.DistributionPath()), cacheDir) {
+		t.Errorf("Expected the usage record to be in the cache directory but got %s", lastUsedPath(toolchain.DistributionPath()))
+	}
+	siblings, err := os.ReadDir(filepath.Dir(toolchain.DistributionPath()))
+	if err != nil || len(siblings) != 1 {
+		t.Errorf("Expected nothing to be written next to the distribution but got %v, %v", siblings, err)
+	}
+}
+
+func TestGradleLogs(t *testing.T) {
+	setTestConfig(t, "cacheDir", t.TempDir())
+	now := time.Now()
+	for i, name := range []string{"gradle-old.log", "gradle-new.log"} {
+		path := filepath.Join(gradleLogsDir(), name)
+		writeTestFile(t, path, "log")
+		modTime := now.Add(time.Duration(i-1) * time.Hour)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	logs, err := GradleLogs()
+	if err != nil || len(logs) != 2 || filepath.Base(logs[0].Path) != "gradle-new.log" {
+		t.Fatalf("Expected the logs most recent first but got %v, %v", logs, err)
+	}
+	selected := SelectForRemoval(logs, "", GcOptions{Keep: 1}, now)
+	if len(selected) != 1 || filepath.Base(selected[0].Path) != "gradle-old.log" {
+		t.Errorf("Expected the old log to be removed but got %v", selected)
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Directory inside the distribution holding the original jars replaced by `toolchain patch`
+const patchBackupDir = ".jbalcomptools-backup"
+
+var toolchainPatchCmd = &cobra.Command{
+	Use:   "patch",
+	Short: "Replace the jars of changed modules in the built distribution",
+	Long: `Replace the jars of changed modules in the built distribution.
+Only the jars of the given modules (or the modules with changed files) are built, and the matching jars in the
+distribution are replaced. The original jars are kept so that they can be restored with --revert.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		toolchain := CurrentToolchain()
+		if toolchain.Kind != CheckoutToolchain {
+			ConsumeError(fmt.Errorf("toolchain %s is a %s, only checkouts can be patched", toolchain.Name, toolchain.Kind))
+		}
+		if !toolchain.IsBuilt() {
+			ConsumeError(fmt.Errorf("toolchain %s is not built yet, run buildTools first", toolchain.Name))
+		}
+		if viper.GetBool("patch_revert") {
+			ConsumeError(RevertPatch(toolchain))
+			return
+		}
+		ConsumeError(withBuildLock(toolchain.Path, func() error {
+			return PatchToolchain(toolchain, viper.GetStringSlice("patch_modules"))
+		}))
+	},
+}
+
+// PatchToolchain builds the jars of the given subprojects and swaps them into the distribution. If no subprojects
+// are given the ones with changed files are patched.
+func PatchToolchain(toolchain Toolchain, modules []string) error {
+	subprojects, err := GradleSubprojects(toolchain.Path)
+	if err != nil {
+		return err
+	}
+	fromChanges := len(modules) == 0
+	if fromChanges {
+		report, err := CheckStaleness(toolchain.Path, toolchain.Version)
+		if err != nil {
+			return err
+		}
+		if modules, err = SubprojectsOf(report.Changes, subprojects); err != nil {
+			return err
+		}
+		if len(modules) == 0 {
+			fmt.Println("No changed modules to patch")
+			return nil
+		}
+	}
+	if err := backupManifest(toolchain); err != nil {
+		return err
+	}
+	version := versionOverride(toolchain.Path, toolchain.Version)
+	for _, module := range modules {
+		projectDir, ok := subprojectDir(module, subprojects)
+		if !ok {
+			return fmt.Errorf("unknown subproject %s", module)
+		}
+		if err := timedGradleTask(toolchain.Path, module+":jar", version, "Built "+module); err != nil {
+			return err
+		}
+		jar, err := builtJar(filepath.Join(toolchain.Path, projectDir))
+		if err != nil {
+			return err
+		}
+		if err := swapJar(toolchain.DistributionPath(), jar); err != nil {
+			return err
+		}
+	}
+	if fromChanges {
+		// The distribution now contains every change so it shouldn't be rebuilt
+		return recordManifest(toolchain.Path, toolchain.Version)
+	}
+	return nil
+}
+
+func subprojectDir(module string, subprojects map[string]string) (string, bool) {
+	for dir, name := range subprojects {
+		if name == module {
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+// builtJar finds the most recently built main jar of a subproject
+func builtJar(projectDir string) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(projectDir, "build", "libs", "*.jar"))
+	if err != nil {
+		return "", err
+	}
+	var newest string
+	var newestInfo os.FileInfo
+	for _, match := range matches {
+		name := filepath.Base(match)
+		if strings.HasSuffix(name, "-sources.jar") || strings.HasSuffix(name, "-javadoc.jar") ||
+			strings.HasSuffix(name, "-tests.jar") {
+			continue
+		}
+		info, err := os.Stat(match)
+		if err != nil {
+			return "", err
+		}
+		if newestInfo == nil || info.ModTime().After(newestInfo.ModTime()) {
+			newest, newestInfo = match, info
+		}
+	}
+	if newest == "" {
+		return "", fmt.Errorf("no jar found in %s", filepath.Join(projectDir, "build", "libs"))
+	}
+	return newest, nil
+}
+
+// swapJar replaces every jar in the distribution with the same name as the given jar, backing up the originals
+func swapJar(distributionPath, jar string) error {
+	name := filepath.Base(jar)
+	var targets []string
+	err := filepath.Walk(distributionPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == patchBackupDir {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() && info.Name() == name {
+			targets = append(targets, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("%s is not part of the distribution at %s", name, distributionPath)
+	}
+	for _, target := range targets {
+		relPath, err := filepath.Rel(distributionPath, target)
+		if err != nil {
+			return err
+		}
+		backup := filepath.Join(distributionPath, patchBackupDir, relPath)
+		// Keep the jar from the original build when patching more than once
+		if _, err := os.Stat(backup); os.IsNotExist(err) {
+			if err := copyFile(target, backup); err != nil {
+				return err
+			}
+		}
+		if err := copyFile(jar, target); err != nil {
+			return err
+		}
+		fmt.Println("Patched", relPath)
+	}
+	return nil
+}
+
+// RevertPatch restores the jars and the source manifest backed up by PatchToolchain
+func RevertPatch(toolchain Toolchain) error {
+	distributionPath := toolchain.DistributionPath()
+	backupRoot := filepath.Join(distributionPath, patchBackupDir)
+	if _, err := os.Stat(backupRoot); os.IsNotExist(err) {
+		fmt.Println("Toolchain is not patched")
+		return nil
+	}
+	err := filepath.Walk(backupRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(backupRoot, path)
+		if err != nil {
+			return err
+		}
+		if relPath == filepath.Base(manifestPath(toolchain.Path, toolchain.Version)) {
+			return copyFile(path, manifestPath(toolchain.Path, toolchain.Version))
+		}
+		fmt.Println("Restored", relPath)
+		return copyFile(path, filepath.Join(distributionPath, relPath))
+	})
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(backupRoot)
+}
+
+// backupManifest keeps the manifest of the original build so that reverting the patch also restores its staleness
+func backupManifest(toolchain Toolchain) error {
+	manifest := manifestPath(toolchain.Path, toolchain.Version)
+	backup := filepath.Join(toolchain.DistributionPath(), patchBackupDir, filepath.Base(manifest))
+	if _, err := os.Stat(backup); err == nil {
+		return nil
+	}
+	if _, err := os.Stat(manifest); os.IsNotExist(err) {
+		return nil
+	}
+	return copyFile(manifest, backup)
+}
+
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func init() {
+	toolchainCmd.AddCommand(toolchainPatchCmd)
+	toolchainPatchCmd.Flags().StringSlice("module", nil, "Gradle path of a subproject to patch, ex: :ballerina-lang (defaults to the modules with changed files)")
+	toolchainPatchCmd.Flags().Bool("revert", false, "Restore the jars replaced by previous patches")
+	viper.BindPFlag("patch_modules", toolchainPatchCmd.Flags().Lookup("module"))
+	viper.BindPFlag("patch_revert", toolchainPatchCmd.Flags().Lookup("revert"))
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readTestFile(t *testing.T, path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return string(content)
+}
+
+func TestPatchAndRevert(t *testing.T) {
+	sourcePath := t.TempDir()
+	version := "1.0.0"
+	toolchain := NewCheckoutToolchain(sourcePath, version)
+	distributionJar := filepath.Join(toolchain.DistributionPath(), "bre", "lib", "ballerina-lang-1.0.0.jar")
+	writeTestFile(t, toolchain.BalPath(), "")
+	writeTestFile(t, distributionJar, "original")
+	writeTestFile(t, manifestPath(sourcePath, version), `{"files":{}}`)
+
+	projectDir := filepath.Join(sourcePath, "compiler", "ballerina-lang")
+	writeTestFile(t, filepath.Join(projectDir, "build", "libs", "ballerina-lang-1.0.0-sources.jar"), "sources")
+	writeTestFile(t, filepath.Join(projectDir, "build", "libs", "ballerina-lang-1.0.0.jar"), "patched")
+
+	jar, err := builtJar(projectDir)
+	if err != nil || filepath.Base(jar) != "ballerina-lang-1.0.0.jar" {
+		t.Fatalf("Expected to find ballerina-lang-1.0.0.jar, but got %s, %v", jar, err)
+	}
+	if err := backupManifest(toolchain); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := swapJar(toolchain.DistributionPath(), jar); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if content := readTestFile(t, distributionJar); content != "patched" {
+		t.Errorf("Expected the distribution jar to be patched, but got %s", content)
+	}
+	writeTestFile(t, manifestPath(sourcePath, version), `{"files":{"a":{}}}`)
+
+	if err := RevertPatch(toolchain); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if content := readTestFile(t, distributionJar); content != "original" {
+		t.Errorf("Expected the distribution jar to be restored, but got %s", content)
+	}
+	if content := readTestFile(t, manifestPath(sourcePath, version)); content != `{"files":{}}` {
+		t.Errorf("Expected the manifest to be restored, but got %s", content)
+	}
+	if _, err := os.Stat(filepath.Join(toolchain.DistributionPath(), patchBackupDir)); !os.IsNotExist(err) {
+		t.Errorf("Expected the backup directory to be removed")
+	}
+}
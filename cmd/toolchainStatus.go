@@ -0,0 +1,201 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var javaVersionPattern = regexp.MustCompile(`version "([^"]+)"`)
+
+// ToolchainStatus is what the tool knows about a toolchain before deciding whether to rebuild it
+type ToolchainStatus struct {
+	Name        string        `json:"name"`
+	Kind        ToolchainKind `json:"kind"`
+	SourcePath  string        `json:"sourcePath"`
+	Version     string        `json:"version"`
+	BalPath     string        `json:"balPath"`
+	BalExists   bool          `json:"balExists"`
+	BuiltAt     *time.Time    `json:"builtAt,omitempty"`
+	Commit      string        `json:"commit,omitempty"`
+	Dirty       bool          `json:"dirty"`
+	Stale       bool          `json:"stale"`
+	StaleReason string        `json:"staleReason,omitempty"`
+	Changes     []FileChange  `json:"changes,omitempty"`
+	Java        string        `json:"java"`
+	JavaVersion string        `json:"javaVersion,omitempty"`
+}
+
+var toolchainStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the current toolchain and whether it needs to be rebuilt",
+	Run: func(cmd *cobra.Command, args []string) {
+		status, err := CurrentToolchainStatus(CurrentToolchainWithoutBuilding())
+		ConsumeError(err)
+		if viper.GetBool("status_json") {
+			content, err := json.MarshalIndent(status, "", "  ")
+			ConsumeError(err)
+			fmt.Println(string(content))
+			return
+		}
+		printToolchainStatus(status, viper.GetBool("status_why"))
+	},
+}
+
+// CurrentToolchainStatus collects the status of the toolchain. Staleness is only checked for checkouts since other
+// toolchains are never rebuilt.
+func CurrentToolchainStatus(toolchain Toolchain) (ToolchainStatus, error) {
+	status := ToolchainStatus{
+		Name:       toolchain.Name,
+		Kind:       toolchain.Kind,
+		SourcePath: toolchain.Path,
+		Version:    toolchain.Version,
+		BalPath:    toolchain.BalPath(),
+		BalExists:  toolchain.IsBuilt(),
+	}
+	if info, ok := toolchainBuildInfo(toolchain); ok {
+		status.BuiltAt = &info.BuiltAt
+		status.Commit = info.Commit
+		status.Dirty = info.Dirty
+	}
+	if toolchain.Kind == CheckoutToolchain {
+		report, err := CheckStaleness(toolchain.Path, toolchain.Version)
+		if err != nil {
+			return status, err
+		}
+		status.Stale = report.IsStale()
+		status.StaleReason = staleReason(report)
+		status.Changes = report.Changes
+	}
+	status.Java = javaExecutable(toolchain)
+	status.JavaVersion, _ = JavaVersion(status.Java)
+	return status, nil
+}
+
+func toolchainBuildInfo(toolchain Toolchain) (BuildInfo, bool) {
+	var path string
+	switch {
+	case toolchain.Kind == CheckoutToolchain:
+		path = checkoutBuildInfoPath(toolchain.Path, toolchain.Version)
+	case strings.HasPrefix(toolchain.Path, revisionsDir()):
+		path = filepath.Join(filepath.Dir(toolchain.Path), "build.json")
+	default:
+		return BuildInfo{}, false
+	}
+	info, err := ReadBuildInfo(path)
+	return info, err == nil
+}
+
+func staleReason(report StalenessReport) string {
+	switch {
+	case !report.IsStale():
+		return ""
+	case report.CompilerMissing:
+		return "the bal executable doesn't exist"
+	case report.UntrackedChanges:
+		return "it was built without a source manifest and there are newer source files"
+	default:
+		return fmt.Sprintf("%d changed files", len(report.Changes))
+	}
+}
+
+// javaExecutable returns the java bal runs on: the JDK bundled with a release, JAVA_HOME or the one on the PATH
+func javaExecutable(toolchain Toolchain) string {
+	if toolchain.Kind == ReleaseToolchain {
+		if matches, _ := filepath.Glob(filepath.Join(toolchain.Path, "dependencies", "jdk*", "bin", "java")); len(matches) > 0 {
+			return matches[len(matches)-1]
+		}
+	}
+	if javaHome := os.Getenv("JAVA_HOME"); javaHome != "" {
+		return filepath.Join(javaHome, "bin", "java")
+	}
+	if path, err := exec.LookPath("java"); err == nil {
+		return path
+	}
+	return "java"
+}
+
+// JavaVersion runs `java -version` and returns the version it reports
+func JavaVersion(java string) (string, error) {
+	out, err := exec.Command(java, "-version").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("unable to run %s: %v", java, err)
+	}
+	version, ok := parseJavaVersion(string(out))
+	if !ok {
+		return "", fmt.Errorf("unable to find the version in the output of %s -version", java)
+	}
+	return version, nil
+}
+
+func parseJavaVersion(output string) (string, bool) {
+	match := javaVersionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+func printToolchainStatus(status ToolchainStatus, why bool) {
+	fmt.Printf("Toolchain:   %s (%s)\n", status.Name, status.Kind)
+	fmt.Printf("Source path: %s\n", status.SourcePath)
+	fmt.Printf("Version:     %s\n", valueOrDash(status.Version))
+	exists := "missing"
+	if status.BalExists {
+		exists = "exists"
+	}
+	fmt.Printf("bal:         %s (%s)\n", status.BalPath, exists)
+	if status.BuiltAt != nil {
+		fmt.Printf("Built at:    %s\n", status.BuiltAt.Format(time.RFC1123))
+		commit := valueOrDash(shortCommit(status.Commit))
+		if status.Dirty {
+			commit += " (with uncommitted changes)"
+		}
+		fmt.Printf("Built from:  %s\n", commit)
+	}
+	fmt.Printf("Java:        %s %s\n", status.Java, valueOrDash(status.JavaVersion))
+	if status.Kind != CheckoutToolchain && !status.BalExists {
+		fmt.Println("Toolchain is not built")
+		return
+	}
+	if status.Kind != CheckoutToolchain {
+		fmt.Printf("Toolchain is a %s and is never rebuilt\n", status.Kind)
+		return
+	}
+	if !status.Stale {
+		fmt.Println("Toolchain is up to date")
+		return
+	}
+	fmt.Println("Toolchain needs to be rebuilt:", status.StaleReason)
+	if len(status.Changes) == 0 {
+		return
+	}
+	if !why {
+		fmt.Println("Use --why to list the changed files")
+		return
+	}
+	for _, change := range status.Changes {
+		fmt.Printf("  %-8s %s\n", change.Kind, change.Path)
+	}
+}
+
+func init() {
+	toolchainCmd.AddCommand(toolchainStatusCmd)
+	toolchainStatusCmd.Flags().Bool("why", false, "List the changed files that would trigger a rebuild")
+	toolchainStatusCmd.Flags().Bool("json", false, "Print the status as JSON")
+	viper.BindPFlag("status_why", toolchainStatusCmd.Flags().Lookup("why"))
+	viper.BindPFlag("status_json", toolchainStatusCmd.Flags().Lookup("json"))
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseJavaVersion(t *testing.T) {
+	testCases := []struct {
+		output   string
+		expected string
+	}{
+		{"openjdk version \"17.0.7\" 2023-04-18\nOpenJDK Runtime Environment Temurin-17.0.7+7 (build 17.0.7+7)\n", "17.0.7"},
+		{"java version \"1.8.0_381\"\nJava(TM) SE Runtime Environment (build 1.8.0_381-b09)\n", "1.8.0_381"},
+	}
+
+	for _, tc := range testCases {
+		actual, ok := parseJavaVersion(tc.output)
+		if !ok || actual != tc.expected {
+			t.Errorf("Expected %s but got %s", tc.expected, actual)
+		}
+	}
+	if _, ok := parseJavaVersion("command not found"); ok {
+		t.Errorf("Expected no version in unrelated output")
+	}
+}
+
+func TestCurrentToolchainStatusOfDistribution(t *testing.T) {
+	distribution := filepath.Join(t.TempDir(), "jballerina-tools-2201.9.0")
+	writeTestFile(t, filepath.Join(distribution, "bin", "bal"), "")
+	toolchain := Toolchain{Name: "extracted", Kind: DistributionToolchain, Path: distribution, Version: "2201.9.0"}
+
+	status, err := CurrentToolchainStatus(toolchain)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if status.BalPath != filepath.Join(distribution, "bin", "bal") || !status.BalExists {
+		t.Errorf("Expected the bal of the distribution to exist but got %s (exists: %v)", status.BalPath, status.BalExists)
+	}
+	if status.Stale || status.BuiltAt != nil {
+		t.Errorf("Expected a distribution without build info that is never stale but got %+v", status)
+	}
+}
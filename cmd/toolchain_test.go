@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRegisteredToolchains(t *testing.T) {
+	setTestConfig(t, "toolchains", map[string]interface{}{
+		"dev":     map[string]interface{}{"kind": "checkout", "path": "/src/ballerina-lang", "version": "2201.9.0"},
+		"release": map[string]interface{}{"kind": "release", "path": "/usr/lib/ballerina", "version": "2201.8.0"},
+		"dist":    map[string]interface{}{"kind": "distribution", "path": "/tmp/jballerina-tools-2201.9.0"},
+	})
+
+	toolchains, err := RegisteredToolchains()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		name            string
+		kind            ToolchainKind
+		expectedBalPath string
+	}{
+		{"dev", CheckoutToolchain, BalPath("/src/ballerina-lang", "2201.9.0")},
+		{"dist", DistributionToolchain, filepath.Join("/tmp/jballerina-tools-2201.9.0", "bin", "bal")},
+		{"release", ReleaseToolchain, filepath.Join("/usr/lib/ballerina", "distributions", "ballerina-2201.8.0", "bin", "bal")},
+	}
+	if len(toolchains) != len(testCases) {
+		t.Fatalf("Expected %d toolchains, but got %v", len(testCases), toolchains)
+	}
+	for i, tc := range testCases {
+		toolchain := toolchains[i]
+		if toolchain.Name != tc.name || toolchain.Kind != tc.kind {
+			t.Errorf("Expected toolchain %s of kind %s, but got %v", tc.name, tc.kind, toolchain)
+		}
+		if toolchain.BalPath() != tc.expectedBalPath {
+			t.Errorf("Expected bal path of %s to be %s, but got %s", tc.name, tc.expectedBalPath, toolchain.BalPath())
+		}
+	}
+
+	resolved, err := ResolveToolchain("release")
+	if err != nil || resolved.Kind != ReleaseToolchain {
+		t.Errorf("Expected to resolve the registered release toolchain, but got %v, %v", resolved, err)
+	}
+}
+
+func TestResolveToolchainFromPath(t *testing.T) {
+	toolchain, err := ResolveToolchain("/path/to/checkout@1.2.3")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if toolchain.Kind != CheckoutToolchain || toolchain.Path != "/path/to/checkout" || toolchain.Version != "1.2.3" {
+		t.Errorf("Expected checkout toolchain at /path/to/checkout with version 1.2.3, but got %v", toolchain)
+	}
+}
+
+func TestLookupToolchainDetectsOnlyMissingVersions(t *testing.T) {
+	sourcePath := t.TempDir()
+	writeTestFile(t, filepath.Join(sourcePath, "gradle.properties"), "version=2201.9.0-SNAPSHOT\n")
+	setTestConfig(t, "toolchains", map[string]interface{}{
+		"detected":   map[string]interface{}{"kind": "checkout", "path": sourcePath},
+		"configured": map[string]interface{}{"kind": "checkout", "path": sourcePath, "version": "2201.8.0"},
+	})
+
+	toolchains, err := RegisteredToolchains()
+	if err != nil || len(toolchains) != 2 || toolchains[1].Version != "" {
+		t.Fatalf("Expected the registry not to detect versions, but got %v, %v", toolchains, err)
+	}
+	testCases := []struct {
+		name    string
+		version string
+	}{
+		{"detected", "2201.9.0-SNAPSHOT"},
+		{"configured", "2201.8.0"},
+	}
+	for _, tc := range testCases {
+		toolchain, err := LookupToolchain(tc.name)
+		if err != nil || toolchain.Version != tc.version {
+			t.Errorf("Expected toolchain %s to have version %s, but got %v, %v", tc.name, tc.version, toolchain, err)
+		}
+	}
+}
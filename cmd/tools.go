@@ -0,0 +1,94 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type toolTarget int
+
+const (
+	// Optional path to a package or file, defaults to the current working directory. Paths inside a package are
+	// resolved to the package.
+	pathTarget toolTarget = iota
+	// Optional path to a file or directory of sources, passed to bal as is
+	sourceTarget
+	// The command doesn't take a target
+	noTarget
+	// One or more names (package, module or class names) that must be provided
+	nameTarget
+)
+
+type balTool struct {
+	command Command
+	use     string
+	short   string
+	target  toolTarget
+}
+
+var balTools = []balTool{
+	{Pack, "pack [path]", "Create a distributable package", pathTarget},
+	{Clean, "clean", "Clean the build artifacts of the current package", noTarget},
+	{Format, "format [path]", "Format Ballerina source files", sourceTarget},
+	{Doc, "doc [path]", "Generate API documentation", pathTarget},
+	{Graph, "graph [path]", "Print the dependency graph", pathTarget},
+	{New, "new <path>", "Create a new Ballerina package", nameTarget},
+	{Add, "add <module-name>", "Add a new module to the current package", nameTarget},
+	{Bindgen, "bindgen <class-name>...", "Generate Ballerina bindings for Java classes", nameTarget},
+}
+
+// toolTargetPath is the path passed to bal for the tool, empty if the tool doesn't take a path
+func toolTargetPath(tool balTool, args []string) string {
+	switch tool.target {
+	case pathTarget:
+		return TargetFromArgs(args)
+	case sourceTarget:
+		if len(args) > 0 {
+			return args[0]
+		}
+		return CurrentWorkingDir()
+	default:
+		return ""
+	}
+}
+
+func newBalToolCommand(tool balTool) *cobra.Command {
+	remoteKey := "remote_" + string(tool.command)
+	cmd := &cobra.Command{
+		Use:   tool.use + " [-- bal-flags...]",
+		Short: tool.short,
+		Run: func(cmd *cobra.Command, args []string) {
+			args, passThrough := SplitPassThroughArgs(cmd, args)
+			if tool.target == nameTarget {
+				if len(args) == 0 {
+					fmt.Printf("Please provide the arguments for bal %s\n", tool.command)
+					os.Exit(1)
+				}
+				passThrough = append(passThrough, args...)
+			}
+			targetPath := toolTargetPath(tool, args)
+			command, err := CreateCommand(CurrentToolchain(), targetPath, tool.command,
+				NewDebugOptions(viper.GetBool(remoteKey), false), passThrough...)
+			ConsumeError(err)
+			ConsumeError(ExecuteCommand(&command))
+		},
+	}
+	cmd.Flags().BoolP("remote", "r", false, "Remote debug the compiler")
+	addBuildFlagsFlag(cmd.Flags())
+	viper.BindPFlag(remoteKey, cmd.Flags().Lookup("remote"))
+	return cmd
+}
+
+func init() {
+	for _, tool := range balTools {
+		rootCmd.AddCommand(newBalToolCommand(tool))
+	}
+}
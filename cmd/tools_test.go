@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestToolTargetPath(t *testing.T) {
+	format := balTool{command: Format, target: sourceTarget}
+	pack := balTool{command: Pack, target: pathTarget}
+	testCases := []struct {
+		tool     balTool
+		args     []string
+		expected string
+	}{
+		{format, []string{"../testData/BalProject/main.bal"}, "../testData/BalProject/main.bal"},
+		{format, []string{"../testData/BalFile"}, "../testData/BalFile"},
+		{pack, []string{"../testData/BalProject/main.bal"}, "../testData/BalProject"},
+		{balTool{command: Clean, target: noTarget}, nil, ""},
+	}
+
+	for _, tc := range testCases {
+		if actual := toolTargetPath(tc.tool, tc.args); actual != tc.expected {
+			t.Errorf("Expected bal %s %v to target %s, but got %s", tc.tool.command, tc.args, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2024 Heshan Padmasiri
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const extractedDistributionPrefix = "jballerina-tools-"
+
+// DetectCheckoutVersion finds the jBallerina version of a ballerina-lang checkout. A version chosen with
+// `buildTools --version` takes precedence until the checkout is built without one, followed by the version in
+// gradle.properties and finally the extracted distributions that have already been built.
+func DetectCheckoutVersion(sourcePath string) (string, error) {
+	if version := recordedBuildVersion(sourcePath); version != "" {
+		return version, nil
+	}
+	if version, err := gradlePropertiesVersion(sourcePath); err == nil && version != "" {
+		return version, nil
+	}
+	versions := extractedDistributionVersions(sourcePath)
+	if len(versions) == 0 {
+		return "", fmt.Errorf("unable to detect the jBallerina version of %s, please set version in the config", sourcePath)
+	}
+	if len(versions) > 1 {
+		fmt.Fprintf(os.Stderr, "Warning: found extracted distributions for versions %s, using the most recent %s\n",
+			strings.Join(versions, ", "), versions[0])
+	}
+	return versions[0], nil
+}
+
+func gradlePropertiesVersion(sourcePath string) (string, error) {
+	file, err := os.Open(filepath.Join(sourcePath, "gradle.properties"))
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if ok && strings.TrimSpace(key) == "version" {
+			return strings.TrimSpace(value), nil
+		}
+	}
+	return "", scanner.Err()
+}
+
+// extractedDistributionVersions returns the versions of the extracted distributions in the checkout, most recently
+// modified first
+func extractedDistributionVersions(sourcePath string) []string {
+	pattern := filepath.Join(extractedDistributionsDir(sourcePath), extractedDistributionPrefix+"*")
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil
+	}
+	modTimes := make(map[string]int64, len(matches))
+	var dirs []string
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		modTimes[match] = info.ModTime().UnixNano()
+		dirs = append(dirs, match)
+	}
+	sort.Slice(dirs, func(i, j int) bool { return modTimes[dirs[i]] > modTimes[dirs[j]] })
+	versions := make([]string, len(dirs))
+	for i, dir := range dirs {
+		versions[i] = strings.TrimPrefix(filepath.Base(dir), extractedDistributionPrefix)
+	}
+	return versions
+}
+
+func extractedDistributionsDir(sourcePath string) string {
+	return filepath.Join(distributionBuildDir(sourcePath), "extracted-distributions")
+}
+
+// distributionBuildDir is the gradle build directory of the jballerina-tools distribution. State recorded by the tool
+// about a checkout lives here so that it is removed together with the distribution by `gradle clean`.
+func distributionBuildDir(sourcePath string) string {
+	return filepath.Join(sourcePath, "distribution", "zip", "jballerina-tools", "build")
+}
+
+func buildVersionRecordPath(sourcePath string) string {
+	return filepath.Join(distributionBuildDir(sourcePath), ".jbalcomptools-version")
+}
+
+// recordBuildVersion remembers the version chosen with `buildTools --version`
+func recordBuildVersion(sourcePath, version string) error {
+	return os.WriteFile(buildVersionRecordPath(sourcePath), []byte(version+"\n"), 0644)
+}
+
+func clearBuildVersion(sourcePath string) error {
+	if err := os.Remove(buildVersionRecordPath(sourcePath)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func recordedBuildVersion(sourcePath string) string {
+	content, err := os.ReadFile(buildVersionRecordPath(sourcePath))
+	if err != nil {
+		return ""
+	}
+	version := strings.TrimSpace(string(content))
+	if !compilerExists(BalPath(sourcePath, version)) {
+		return ""
+	}
+	return version
+}
+
+// versionOverride returns the version to pass to gradle so that the checkout is built as the given version, or an
+// empty string if that is already the version in gradle.properties
+func versionOverride(sourcePath, version string) string {
+	if defaultVersion, err := gradlePropertiesVersion(sourcePath); err == nil && defaultVersion == version {
+		return ""
+	}
+	return version
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDetectCheckoutVersionFromGradleProperties(t *testing.T) {
+	sourcePath := t.TempDir()
+	properties := "group=org.ballerinalang\nversion=2201.9.0-SNAPSHOT\n"
+	if err := os.WriteFile(filepath.Join(sourcePath, "gradle.properties"), []byte(properties), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	version, err := DetectCheckoutVersion(sourcePath)
+	if err != nil || version != "2201.9.0-SNAPSHOT" {
+		t.Errorf("Expected version to be 2201.9.0-SNAPSHOT, but got %s, %v", version, err)
+	}
+}
+
+func TestDetectCheckoutVersionFromDistributions(t *testing.T) {
+	sourcePath := t.TempDir()
+	now := time.Now()
+	for i, version := range []string{"2201.8.0", "2201.9.0"} {
+		dir := filepath.Join(extractedDistributionsDir(sourcePath), extractedDistributionPrefix+version)
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		modTime := now.Add(time.Duration(i) * time.Hour)
+		if err := os.Chtimes(dir, modTime, modTime); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	version, err := DetectCheckoutVersion(sourcePath)
+	if err != nil || version != "2201.9.0" {
+		t.Errorf("Expected version to be 2201.9.0, but got %s, %v", version, err)
+	}
+}
+
+func TestDetectCheckoutVersionFailure(t *testing.T) {
+	if _, err := DetectCheckoutVersion(t.TempDir()); err == nil {
+		t.Errorf("Expected an error when the version can't be detected")
+	}
+}
+
+func TestDetectCheckoutVersionPrefersRecordedBuildVersion(t *testing.T) {
+	sourcePath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(sourcePath, "gradle.properties"), []byte("version=2201.9.0\n"), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	balPath := BalPath(sourcePath, "2201.9.0-custom")
+	if err := os.MkdirAll(filepath.Dir(balPath), os.ModePerm); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := os.WriteFile(balPath, nil, 0755); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := recordBuildVersion(sourcePath, "2201.9.0-custom"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	version, err := DetectCheckoutVersion(sourcePath)
+	if err != nil || version != "2201.9.0-custom" {
+		t.Errorf("Expected version to be 2201.9.0-custom, but got %s, %v", version, err)
+	}
+	if override := versionOverride(sourcePath, "2201.9.0"); override != "" {
+		t.Errorf("Expected no version override for the gradle.properties version, but got %s", override)
+	}
+}
+
+func TestDetectCheckoutVersionFollowsGradlePropertiesAfterPlainBuild(t *testing.T) {
+	sourcePath := t.TempDir()
+	writeTestFile(t, filepath.Join(sourcePath, "gradle.properties"), "version=2201.9.0-SNAPSHOT\n")
+	writeTestFile(t, BalPath(sourcePath, "2201.9.0-SNAPSHOT"), "")
+	writeTestFile(t, BalPath(sourcePath, "2201.9.0-custom"), "")
+	if err := recordBuildVersion(sourcePath, "2201.9.0-custom"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// An automatic rebuild of the gradle.properties version replaces the version chosen with buildTools --version
+	finishBuild(sourcePath, "", nil)
+	writeTestFile(t, filepath.Join(sourcePath, "gradle.properties"), "version=2201.10.0-SNAPSHOT\n")
+
+	version, err := DetectCheckoutVersion(sourcePath)
+	if err != nil || version != "2201.10.0-SNAPSHOT" {
+		t.Errorf("Expected version to be 2201.10.0-SNAPSHOT, but got %s, %v", version, err)
+	}
+	if override := versionOverride(sourcePath, version); override != "" {
+		t.Errorf("Expected no version override after the bump, but got %s", override)
+	}
+}
@@ -0,0 +1,3 @@
+public function greeting() returns string {
+    return "Hello";
+}